@@ -0,0 +1,190 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	"github.com/owasp-amass/config/config"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+const asnUsageMsg = "asn [options] -org string"
+
+type asnArgs struct {
+	Organization string
+	Options      struct {
+		JSON    bool
+		NoColor bool
+		Silent  bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+	}
+}
+
+// orgASN is an autonomous system, found in the graph database, managed by an organization matching the search.
+type orgASN struct {
+	ASN         int      `json:"asn"`
+	Description string   `json:"description"`
+	Netblocks   []string `json:"netblocks"`
+}
+
+func runASNCommand(clArgs []string) {
+	var args asnArgs
+	var help1, help2 bool
+	asnCommand := flag.NewFlagSet("asn", flag.ContinueOnError)
+
+	asnBuf := new(bytes.Buffer)
+	asnCommand.SetOutput(asnBuf)
+
+	asnCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	asnCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	asnCommand.StringVar(&args.Organization, "org", "", "Search string provided against the organizations stored in the graph database")
+	asnCommand.BoolVar(&args.Options.JSON, "json", false, "Print each autonomous system as a JSON line")
+	asnCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	asnCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	asnCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file. Additional details below")
+	asnCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+
+	if len(clArgs) < 1 {
+		commandUsage(asnUsageMsg, asnCommand, asnBuf)
+		return
+	}
+	if err := asnCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 || args.Organization == "" {
+		commandUsage(asnUsageMsg, asnCommand, asnBuf)
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	// The search only reads the graph database
+	amassnet.SetOffline(true)
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err != nil && args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+
+	db := openGraphDatabase(cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	results := searchOrgASNs(db, args.Organization)
+	if len(results) == 0 {
+		r.Fprintf(color.Error, "No autonomous systems were found for organizations matching %s\n", args.Organization)
+		return
+	}
+
+	for _, res := range results {
+		printOrgASN(res, args.Options.JSON)
+	}
+}
+
+// searchOrgASNs returns the autonomous systems managed by the RIR organizations with names containing the
+// search string, ignoring the case, along with the netblocks they announce. The results are sorted by ASN.
+func searchOrgASNs(g *netmap.Graph, search string) []*orgASN {
+	// An error is also returned when no organizations have been stored
+	orgs, err := g.DB.FindByType(oam.RIROrg, time.Time{})
+	if err != nil {
+		return nil
+	}
+
+	search = strings.ToLower(search)
+	found := make(map[int]*orgASN)
+	for _, org := range orgs {
+		rir, ok := org.Asset.(network.RIROrganization)
+		if !ok || !strings.Contains(strings.ToLower(rir.Name), search) {
+			continue
+		}
+
+		rels, err := g.DB.IncomingRelations(org, time.Time{}, "managed_by")
+		if err != nil {
+			continue
+		}
+
+		for _, rel := range rels {
+			a, err := g.DB.FindById(rel.FromAsset.ID, time.Time{})
+			if err != nil {
+				continue
+			}
+
+			as, ok := a.Asset.(network.AutonomousSystem)
+			if !ok {
+				continue
+			}
+			if _, dup := found[as.Number]; dup {
+				continue
+			}
+
+			res := &orgASN{
+				ASN:         as.Number,
+				Description: rir.Name,
+			}
+			if nbrels, err := g.DB.OutgoingRelations(a, time.Time{}, "announces"); err == nil {
+				for _, nbrel := range nbrels {
+					if nb, err := g.DB.FindById(nbrel.ToAsset.ID, time.Time{}); err == nil {
+						if netblock, ok := nb.Asset.(network.Netblock); ok {
+							res.Netblocks = append(res.Netblocks, netblock.Cidr.String())
+						}
+					}
+				}
+			}
+
+			sort.Strings(res.Netblocks)
+			found[as.Number] = res
+		}
+	}
+
+	results := make([]*orgASN, 0, len(found))
+	for _, res := range found {
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ASN < results[j].ASN })
+	return results
+}
+
+func printOrgASN(res *orgASN, asJSON bool) {
+	if asJSON {
+		if data, err := json.Marshal(res); err == nil {
+			fmt.Fprintln(color.Output, string(data))
+		}
+		return
+	}
+
+	fmt.Fprintf(color.Output, "%s%s %s %s\n", blue("ASN: "), yellow(strconv.Itoa(res.ASN)), green("-"), green(res.Description))
+	for _, cidr := range res.Netblocks {
+		fmt.Fprintf(color.Output, "%s\n", yellow(fmt.Sprintf("\t%s", cidr)))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// The policies applied by the output buffer when the sinks fall behind the enumeration.
+const (
+	outputOnFullBlock = "block"
+	outputOnFullDrop  = "drop"
+)
+
+// The number of results held for the output sinks, unless the output_buffer size is provided.
+const defaultOutputBufferSize = 1000
+
+// outputBuffer is the bounded queue between the extraction of the results and the output sinks,
+// which keeps a slow sink, such as a remote webhook, from growing the memory without limit.
+type outputBuffer struct {
+	queue   chan *assetRelation
+	onFull  string
+	timeout time.Duration
+	dropped int64
+}
+
+// loadOutputBuffer returns the buffer described by the output_buffer section of the configuration options.
+// When the buffer is full, the block policy waits for the sinks, giving up on the result once the optional
+// timeout expires, while the drop policy discards the result immediately.
+func loadOutputBuffer(cfg *config.Config) (*outputBuffer, error) {
+	size := defaultOutputBufferSize
+	onFull := outputOnFullBlock
+	var timeout time.Duration
+
+	if raw, ok := cfg.Options["output_buffer"]; ok {
+		settings, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("output_buffer is not a map[string]interface{}")
+		}
+
+		if raw, ok := settings["size"]; ok {
+			num, ok := raw.(int)
+			if !ok || num < 1 {
+				return nil, fmt.Errorf("output_buffer size is not a number greater than zero")
+			}
+			size = num
+		}
+		if raw, ok := settings["on_full"]; ok {
+			policy, ok := raw.(string)
+			if !ok || (policy != outputOnFullBlock && policy != outputOnFullDrop) {
+				return nil, fmt.Errorf("output_buffer on_full must be %s or %s", outputOnFullBlock, outputOnFullDrop)
+			}
+			onFull = policy
+		}
+		if raw, ok := settings["timeout"]; ok {
+			secs, ok := raw.(int)
+			if !ok || secs < 0 {
+				return nil, fmt.Errorf("output_buffer timeout is not a number of seconds")
+			}
+			timeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	return &outputBuffer{
+		queue:   make(chan *assetRelation, size),
+		onFull:  onFull,
+		timeout: timeout,
+	}, nil
+}
+
+// Put adds the result to the buffer and returns false when it was dropped.
+func (b *outputBuffer) Put(rel *assetRelation) bool {
+	select {
+	case b.queue <- rel:
+		return true
+	default:
+	}
+
+	if b.onFull == outputOnFullBlock {
+		if b.timeout == 0 {
+			b.queue <- rel
+			return true
+		}
+
+		t := time.NewTimer(b.timeout)
+		defer t.Stop()
+
+		select {
+		case b.queue <- rel:
+			return true
+		case <-t.C:
+		}
+	}
+
+	atomic.AddInt64(&b.dropped, 1)
+	return false
+}
+
+// Results returns the channel the sinks receive the results from.
+func (b *outputBuffer) Results() <-chan *assetRelation {
+	return b.queue
+}
+
+// Close lets the sinks know that no more results will be added.
+func (b *outputBuffer) Close() {
+	close(b.queue)
+}
+
+// Dropped returns the number of results that did not reach the sinks.
+func (b *outputBuffer) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
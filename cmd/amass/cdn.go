@@ -0,0 +1,80 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/amass/v4/resources"
+	"github.com/owasp-amass/config/config"
+)
+
+// loadCDNRanges returns the CDN and shared hosting ranges embedded in the binary, preceded by the
+// ranges read from the file provided by the cdn_ranges option.
+func loadCDNRanges(cfg *config.Config) ([]*resources.CDNRange, error) {
+	ranges, err := resources.GetCDNRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := cfg.Options["cdn_ranges"]
+	if !ok {
+		return ranges, nil
+	}
+
+	path, ok := raw.(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("cdn_ranges is not a string")
+	}
+
+	abs, err := cfg.AbsPathFromConfigDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the absolute path of the cdn_ranges file: %v", err)
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the cdn_ranges file: %v", err)
+	}
+	defer f.Close()
+
+	custom, err := resources.ParseCDNRanges(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the cdn_ranges file: %v", err)
+	}
+	return append(custom, ranges...), nil
+}
+
+// cdnProvider returns the provider of the first range containing the address, or an empty string.
+func cdnProvider(ranges []*resources.CDNRange, ip net.IP) string {
+	for _, r := range ranges {
+		if r.CIDR.Contains(ip) {
+			return r.Provider
+		}
+	}
+	return ""
+}
+
+// markCDNAddrs flags the addresses of the names that belong to a CDN or shared hosting range.
+func markCDNAddrs(names []*requests.Output, ranges []*resources.CDNRange) {
+	for _, o := range names {
+		for i := range o.Addresses {
+			o.Addresses[i].CDN = cdnProvider(ranges, o.Addresses[i].Address)
+		}
+	}
+}
+
+// onlyCDNAddrs returns true when the name has addresses and all of them belong to a CDN range.
+func onlyCDNAddrs(o *requests.Output) bool {
+	for _, a := range o.Addresses {
+		if a.CDN == "" {
+			return false
+		}
+	}
+	return len(o.Addresses) > 0
+}
@@ -0,0 +1,75 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+	"github.com/owasp-amass/config/config"
+)
+
+// loadCheckpointInterval returns the number of minutes between the checkpoint summaries provided by
+// the checkpoint_interval option. Zero is returned when the summaries have not been requested.
+func loadCheckpointInterval(cfg *config.Config) (time.Duration, error) {
+	raw, ok := cfg.Options["checkpoint_interval"]
+	if !ok {
+		return 0, nil
+	}
+
+	mins, ok := raw.(int)
+	if !ok || mins < 0 {
+		return 0, fmt.Errorf("checkpoint_interval is not a number of minutes")
+	}
+	return time.Duration(mins) * time.Minute, nil
+}
+
+// checkpointCounts tallies the assets in the results output so far by the enumeration.
+type checkpointCounts struct {
+	names *stringset.Set
+	addrs *stringset.Set
+	asns  *stringset.Set
+}
+
+func newCheckpointCounts() *checkpointCounts {
+	return &checkpointCounts{
+		names: stringset.New(),
+		addrs: stringset.New(),
+		asns:  stringset.New(),
+	}
+}
+
+// Add counts the assets on both ends of the relation.
+func (c *checkpointCounts) Add(rel *assetRelation) {
+	for _, a := range [][2]string{{rel.From, rel.FromType}, {rel.To, rel.ToType}} {
+		switch a[1] {
+		case "FQDN":
+			c.names.Insert(a[0])
+		case "IPAddress":
+			c.addrs.Insert(a[0])
+		case "ASN":
+			c.asns.Insert(a[0])
+		}
+	}
+}
+
+// Print writes the checkpoint summary to stderr, which is discarded by the silent flag, and to the log.
+func (c *checkpointCounts) Print(logger *log.Logger, start time.Time) {
+	elapsed := time.Since(start)
+
+	format.FprintCheckpointSummary(color.Error, elapsed, c.names.Len(), c.addrs.Len(), c.asns.Len())
+	logger.Printf("Checkpoint after %s: %d names, %d addresses, %d ASNs",
+		elapsed.Round(time.Second), c.names.Len(), c.addrs.Len(), c.asns.Len())
+}
+
+func (c *checkpointCounts) Close() {
+	c.names.Close()
+	c.addrs.Close()
+	c.asns.Close()
+}
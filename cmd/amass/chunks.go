@@ -0,0 +1,196 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/enum"
+)
+
+// domainChunks partitions the root domain names into chunks of the provided size.
+// A size of zero returns all the domain names in a single chunk.
+func domainChunks(domains []string, size int) [][]string {
+	if size <= 0 || len(domains) <= size {
+		return [][]string{domains}
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(domains); start += size {
+		end := start + size
+		if end > len(domains) {
+			end = len(domains)
+		}
+		chunks = append(chunks, domains[start:end])
+	}
+	return chunks
+}
+
+// The status of each root domain name shown in the table printed once the enumeration finishes.
+const (
+	domainSucceeded = "succeeded"
+	domainFailed    = "failed"
+	domainSkipped   = "skipped"
+)
+
+// domainStatus is the outcome of the enumeration for a single root domain name.
+type domainStatus struct {
+	Domain string
+	Status string
+	Err    error
+}
+
+// startEnumChunks executes an enumeration for each chunk of root domain names, using the number of workers
+// requested. The chunks share the system and graph database of the provided enumeration. When failFast is
+// false, a failed chunk does not stop the others and its domain names are enumerated again one at a time,
+// so the failure is isolated to the domain names causing it. The status of each domain name is returned
+// along with the first error.
+func startEnumChunks(ctx context.Context, e *enum.Enumeration, chunks [][]string, workers int, failFast bool) ([]*domainStatus, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if len(chunks) <= 1 {
+		// The scope may only contain addresses and ASNs
+		if len(chunks) == 0 || len(chunks[0]) == 0 {
+			return nil, e.Start(ctx)
+		}
+
+		statuses := enumChunk(ctx, e, chunks[0], true, failFast)
+		return statuses, firstDomainError(statuses)
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var finished int
+	var statuses []*domainStatus
+	jobs := make(chan int, len(chunks))
+	total := strconv.Itoa(len(chunks))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range jobs {
+				results := enumChunk(ctx, e, chunks[idx], false, failFast)
+				failed := firstDomainError(results) != nil
+				if failed && failFast {
+					cancel()
+				}
+
+				lock.Lock()
+				statuses = append(statuses, results...)
+				finished++
+				if !failed {
+					fmt.Fprintf(color.Error, "%s %s %s %s\n", green("Chunk"), yellow(strconv.Itoa(finished)+"/"+total),
+						green("finished:"), strings.Join(chunks[idx], ", "))
+				} else {
+					fmt.Fprintf(color.Error, "%s %s %s %s\n", green("Chunk"), yellow(strconv.Itoa(finished)+"/"+total),
+						r.Sprint("failed:"), strings.Join(chunks[idx], ", "))
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	for idx := range chunks {
+		jobs <- idx
+	}
+	close(jobs)
+
+	wg.Wait()
+	sortDomainStatuses(statuses, chunks)
+	return statuses, firstDomainError(statuses)
+}
+
+// enumChunk enumerates the chunk of root domain names, using the provided enumeration itself when whole
+// is true. When the chunk fails and failFast is false, the domain names are enumerated one at a time.
+func enumChunk(ctx context.Context, e *enum.Enumeration, chunk []string, whole, failFast bool) []*domainStatus {
+	if ctx.Err() != nil || e.Stopped() {
+		return chunkStatuses(chunk, domainSkipped, nil)
+	}
+
+	var err error
+	if whole {
+		err = e.Start(ctx)
+	} else {
+		err = e.NewChunk(chunk).Start(ctx)
+	}
+	if err == nil {
+		return chunkStatuses(chunk, domainSucceeded, nil)
+	}
+	if failFast || len(chunk) == 1 || ctx.Err() != nil {
+		e.Config.Log.Printf("The enumeration of %s failed: %v", strings.Join(chunk, ", "), err)
+		return chunkStatuses(chunk, domainFailed, err)
+	}
+
+	e.Config.Log.Printf("The enumeration of %s failed: %v; the domain names will be enumerated one at a time",
+		strings.Join(chunk, ", "), err)
+	var statuses []*domainStatus
+	for _, domain := range chunk {
+		statuses = append(statuses, enumChunk(ctx, e, []string{domain}, false, failFast)...)
+	}
+	return statuses
+}
+
+func chunkStatuses(chunk []string, status string, err error) []*domainStatus {
+	statuses := make([]*domainStatus, 0, len(chunk))
+	for _, domain := range chunk {
+		statuses = append(statuses, &domainStatus{
+			Domain: domain,
+			Status: status,
+			Err:    err,
+		})
+	}
+	return statuses
+}
+
+func firstDomainError(statuses []*domainStatus) error {
+	for _, s := range statuses {
+		if s.Err != nil {
+			return fmt.Errorf("%s: %v", s.Domain, s.Err)
+		}
+	}
+	return nil
+}
+
+// sortDomainStatuses puts the statuses in the order the domain names were provided.
+func sortDomainStatuses(statuses []*domainStatus, chunks [][]string) {
+	order := make(map[string]int)
+	for _, chunk := range chunks {
+		for _, domain := range chunk {
+			order[domain] = len(order)
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return order[statuses[i].Domain] < order[statuses[j].Domain] })
+}
+
+// printDomainStatuses writes the table containing the outcome of the enumeration for each root domain name.
+func printDomainStatuses(statuses []*domainStatus) {
+	width := len("Domain")
+	for _, s := range statuses {
+		if len(s.Domain) > width {
+			width = len(s.Domain)
+		}
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", blue(fmt.Sprintf("%-*s  %s", width, "Domain", "Status")))
+	for _, s := range statuses {
+		status := green(s.Status)
+		switch s.Status {
+		case domainFailed:
+			status = r.Sprint(s.Status + ": " + s.Err.Error())
+		case domainSkipped:
+			status = yellow(s.Status)
+		}
+		fmt.Fprintf(color.Error, "%s  %s\n", fmt.Sprintf("%-*s", width, s.Domain), status)
+	}
+}
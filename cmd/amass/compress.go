@@ -0,0 +1,87 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+const gzipExt = ".gz"
+
+// outputFile is an output file that is optionally compressed with gzip as it is written.
+type outputFile struct {
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// createOutputFile creates or truncates the file at path. The output is compressed when requested
+// or when the path ends with the .gz extension, which is added to the path when missing.
+func createOutputFile(path string, compress bool) (*outputFile, error) {
+	if compress && !strings.HasSuffix(path, gzipExt) {
+		path += gzipExt
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &outputFile{file: f}
+	if strings.HasSuffix(path, gzipExt) {
+		out.gz = gzip.NewWriter(f)
+	}
+	return out, nil
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	if o.gz != nil {
+		return o.gz.Write(p)
+	}
+	return o.file.Write(p)
+}
+
+// Close flushes the compressed stream, when used, and closes the file.
+func (o *outputFile) Close() error {
+	if o.gz != nil {
+		if err := o.gz.Close(); err != nil {
+			_ = o.file.Close()
+			return err
+		}
+	}
+
+	_ = o.file.Sync()
+	return o.file.Close()
+}
+
+// openInputFile opens the file at path, transparently decompressing it when the path ends with the .gz extension.
+func openInputFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, gzipExt) {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &gzipInputFile{Reader: gz, file: f}, nil
+}
+
+type gzipInputFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipInputFile) Close() error {
+	_ = g.Reader.Close()
+	return g.file.Close()
+}
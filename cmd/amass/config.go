@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/owasp-amass/config/config"
+	"gopkg.in/yaml.v3"
+)
+
+// scopeExtensions contains the scope settings that are not handled by the config package.
+type scopeExtensions struct {
+	OnlyInCIDRStrings []string     `yaml:"only_in_cidrs,omitempty"`
+	OnlyInCIDRs       []*net.IPNet `yaml:"-"`
+}
+
+// loadScopeExtensions reads the additional scope settings from the configuration file used by cfg.
+func loadScopeExtensions(cfg *config.Config) (*scopeExtensions, error) {
+	ext := new(scopeExtensions)
+
+	if cfg.Filepath == "" {
+		return ext, nil
+	}
+	if finfo, err := os.Stat(cfg.Filepath); err != nil || finfo.IsDir() {
+		return ext, nil
+	}
+
+	data, err := os.ReadFile(cfg.Filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the main configuration file: %v", err)
+	}
+
+	var c struct {
+		Scope *scopeExtensions `yaml:"scope,omitempty"`
+	}
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error mapping configuration settings to internal values: %v", err)
+	}
+	if c.Scope == nil {
+		return ext, nil
+	}
+	ext = c.Scope
+
+	for _, str := range ext.OnlyInCIDRStrings {
+		_, cidr, err := net.ParseCIDR(str)
+		if err != nil {
+			return nil, fmt.Errorf("scope.only_in_cidrs contains an invalid CIDR: %s", str)
+		}
+		ext.OnlyInCIDRs = append(ext.OnlyInCIDRs, cidr)
+	}
+	return ext, nil
+}
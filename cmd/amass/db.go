@@ -0,0 +1,173 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/amass/v4/systems"
+	"github.com/owasp-amass/config/config"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+const (
+	dbUsageMsg    = "db [options] -reenrich"
+	dbSinceLayout = "2006-01-02"
+)
+
+type dbArgs struct {
+	Since   string
+	Options struct {
+		NoColor  bool
+		Offline  bool
+		Reenrich bool
+		Silent   bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+	}
+}
+
+func runDBCommand(clArgs []string) {
+	var args dbArgs
+	var help1, help2 bool
+	dbCommand := flag.NewFlagSet("db", flag.ContinueOnError)
+
+	dbBuf := new(bytes.Buffer)
+	dbCommand.SetOutput(dbBuf)
+
+	dbCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	dbCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	dbCommand.BoolVar(&args.Options.Reenrich, "reenrich", false, "Update the ASN and netblock information of the stored addresses")
+	dbCommand.StringVar(&args.Since, "since", "", "Only update addresses seen since this date (YYYY-MM-DD)")
+	dbCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	dbCommand.BoolVar(&args.Options.Offline, "offline", false, "Never attempt network access while maintaining the graph database")
+	dbCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	dbCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file. Additional details below")
+	dbCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+
+	if len(clArgs) < 1 {
+		commandUsage(dbUsageMsg, dbCommand, dbBuf)
+		return
+	}
+	if err := dbCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 || !args.Options.Reenrich {
+		commandUsage(dbUsageMsg, dbCommand, dbBuf)
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if args.Options.Offline {
+		amassnet.SetOffline(true)
+	}
+
+	var since time.Time
+	if args.Since != "" {
+		t, err := time.Parse(dbSinceLayout, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "The since date must be provided as YYYY-MM-DD: %v\n", err)
+			os.Exit(1)
+		}
+		since = t
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err != nil && args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+
+	db := openGraphDatabase(cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	cache := requests.NewASNCache()
+	if err := systems.LoadASNCache(cache); err != nil {
+		r.Fprintf(color.Error, "Failed to load the ASN data: %v\n", err)
+		os.Exit(1)
+	}
+
+	total, changed, err := reenrichAddresses(context.Background(), db, cache, since)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to re-enrich the stored addresses: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(color.Error, "%s%s %s%s\n", yellow(total), green(" addresses re-enriched,"),
+		yellow(changed), green(" with updated infrastructure information"))
+}
+
+// reenrichAddresses looks up every address stored in the graph against the ASN cache and updates
+// the infrastructure records. It returns the number of addresses processed and how many changed.
+func reenrichAddresses(ctx context.Context, g *netmap.Graph, cache *requests.ASNCache, since time.Time) (int, int, error) {
+	assets, err := g.DB.FindByType(oam.IPAddress, since)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total, changed int
+	for _, a := range assets {
+		ip, ok := a.Asset.(network.IPAddress)
+		if !ok {
+			continue
+		}
+
+		addr := ip.Address.String()
+		entry := cache.AddrSearch(addr)
+		if entry == nil || entry.Prefix == "" {
+			continue
+		}
+		total++
+
+		var current bool
+		// Remove the stale netblocks that no longer contain the address
+		if rels, err := g.DB.IncomingRelations(a, time.Time{}, "contains"); err == nil {
+			for _, rel := range rels {
+				from, err := g.DB.FindById(rel.FromAsset.ID, time.Time{})
+				if err != nil {
+					continue
+				}
+				if nb, ok := from.Asset.(network.Netblock); ok && nb.Cidr.String() == entry.Prefix {
+					current = true
+					continue
+				}
+				_ = g.DB.DeleteRelation(rel.ID)
+			}
+		}
+		if !current {
+			changed++
+		}
+
+		if err := g.UpsertInfrastructure(ctx, entry.ASN, entry.Description, addr, entry.Prefix); err != nil {
+			return total, changed, err
+		}
+	}
+	return total, changed, nil
+}
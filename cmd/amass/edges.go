@@ -0,0 +1,171 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+	"github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// edgeQuery describes a traversal of the graph starting from a single entity.
+type edgeQuery struct {
+	Entity   string
+	Relation string
+	Depth    int
+	Incoming bool
+	JSON     bool
+}
+
+// parseEntity converts the entity provided on the command-line into the asset it identifies.
+// Addresses, CIDRs and ASNs (e.g. AS13335) are recognized, and everything else is treated as a FQDN.
+func parseEntity(entity string) oam.Asset {
+	if ip, err := netip.ParseAddr(entity); err == nil {
+		ip = ip.Unmap()
+		t := "IPv4"
+		if ip.Is6() {
+			t = "IPv6"
+		}
+		return network.IPAddress{Address: ip, Type: t}
+	}
+	if prefix, err := netip.ParsePrefix(entity); err == nil {
+		prefix = prefix.Masked()
+		t := "IPv4"
+		if prefix.Addr().Is6() {
+			t = "IPv6"
+		}
+		return network.Netblock{Cidr: prefix, Type: t}
+	}
+	if upper := strings.ToUpper(entity); strings.HasPrefix(upper, "AS") {
+		if num, err := strconv.Atoi(upper[2:]); err == nil {
+			return network.AutonomousSystem{Number: num}
+		}
+	}
+	return domain.FQDN{Name: strings.ToLower(entity)}
+}
+
+// traverseEdges returns the relations reachable from the queried entity, following edges of the
+// requested relation type (all types when empty) up to the requested depth.
+func traverseEdges(db *netmap.Graph, q *edgeQuery) ([]*assetRelation, error) {
+	start, err := db.DB.FindByContent(parseEntity(q.Entity), time.Time{})
+	if err != nil || len(start) == 0 {
+		return nil, fmt.Errorf("the entity %s was not found in the graph database", q.Entity)
+	}
+
+	var rtypes []string
+	if q.Relation != "" {
+		rtypes = append(rtypes, q.Relation)
+	}
+
+	var output []*assetRelation
+	visited := make(map[string]struct{})
+	for _, a := range start {
+		visited[a.ID] = struct{}{}
+	}
+
+	current := start
+	for depth := 0; depth < q.Depth && len(current) > 0; depth++ {
+		var next []*types.Asset
+
+		for _, a := range current {
+			var rels []*types.Relation
+			if q.Incoming {
+				rels, err = db.DB.IncomingRelations(a, time.Time{}, rtypes...)
+			} else {
+				rels, err = db.DB.OutgoingRelations(a, time.Time{}, rtypes...)
+			}
+			if err != nil {
+				continue
+			}
+
+			for _, rel := range rels {
+				id := rel.ToAsset.ID
+				if q.Incoming {
+					id = rel.FromAsset.ID
+				}
+
+				other, err := db.DB.FindById(id, time.Time{})
+				if err != nil {
+					continue
+				}
+
+				from, to := a, other
+				if q.Incoming {
+					from, to = other, a
+				}
+				fromstr, fromtype := assetNameAndType(from)
+				tostr, totype := assetNameAndType(to)
+				output = append(output, &assetRelation{
+					RelationRecord: format.RelationRecord{
+						From:     fromstr,
+						FromType: fromtype,
+						Relation: rel.Type,
+						To:       tostr,
+						ToType:   totype,
+					},
+					FromID: from.ID,
+					ToID:   to.ID,
+				})
+
+				if _, found := visited[other.ID]; !found {
+					visited[other.ID] = struct{}{}
+					next = append(next, other)
+				}
+			}
+		}
+		current = next
+	}
+	return output, nil
+}
+
+// showEdges prints the relations reachable from the entity identified by the query.
+func showEdges(db *netmap.Graph, q *edgeQuery, outfile *os.File) {
+	rels, err := traverseEdges(db, q)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if q.JSON {
+		records := make([]*format.RelationRecord, 0, len(rels))
+		for _, rel := range rels {
+			records = append(records, &rel.RelationRecord)
+		}
+
+		data, err := json.Marshal(format.NewGraphRecord(records))
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(color.Output, string(data))
+		if outfile != nil {
+			fmt.Fprintln(outfile, string(data))
+		}
+		return
+	}
+	if len(rels) == 0 {
+		r.Println("No edges were discovered")
+		return
+	}
+
+	for _, rel := range rels {
+		fmt.Fprintln(color.Output, rel.String())
+		if outfile != nil {
+			fmt.Fprintf(outfile, "%s (%s) --> %s --> %s (%s)\n", rel.From, rel.FromType, rel.Relation, rel.To, rel.ToType)
+		}
+	}
+}
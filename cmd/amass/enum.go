@@ -17,17 +17,22 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/caffix/netmap"
+	"github.com/caffix/service"
 	"github.com/caffix/stringset"
 	"github.com/fatih/color"
+	"github.com/google/uuid"
 	"github.com/owasp-amass/amass/v4/datasrcs"
 	"github.com/owasp-amass/amass/v4/enum"
 	"github.com/owasp-amass/amass/v4/format"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/amass/v4/resources"
 	"github.com/owasp-amass/amass/v4/systems"
 	"github.com/owasp-amass/config/config"
@@ -35,10 +40,17 @@ import (
 
 const enumUsageMsg = "enum [options] -d DOMAIN"
 
+// progressInterval is how often the live counts are printed by the verbose output.
+const progressInterval = 5 * time.Second
+
 type enumArgs struct {
 	Addresses         format.ParseIPs
 	ASNs              format.ParseInts
+	CDNRanges         []*resources.CDNRange
 	CIDRs             format.ParseCIDRs
+	Checkpoint        time.Duration
+	ChunkSize         int
+	DNSBudget         int
 	AltWordList       *stringset.Set
 	AltWordListMask   *stringset.Set
 	BruteWordList     *stringset.Set
@@ -46,29 +58,49 @@ type enumArgs struct {
 	Blacklist         *stringset.Set
 	Domains           *stringset.Set
 	Excluded          *stringset.Set
+	ExcludedNames     *stringset.Set
+	FirstN            int
+	Formats           format.ParseStrings
 	Included          *stringset.Set
 	Interface         string
 	MaxDNSQueries     int
+	OnlyInCIDRs       []*net.IPNet
 	ResolverQPS       int
+	Retry             string
 	TrustedQPS        int
 	MaxDepth          int
 	MinForRecursive   int
+	MinScore          float64
 	Names             *stringset.Set
 	Ports             format.ParseInts
 	Resolvers         *stringset.Set
+	RunID             string
+	RunAssets         *runAssetsLog
+	Tags              format.ParseTags
+	TestSource        string
 	Trusted           *stringset.Set
 	Timeout           int
+	Workers           int
 	Options           struct {
 		Active       bool
 		Alterations  bool
 		BruteForcing bool
+		CertNames    bool
 		DemoMode     bool
+		FailFast     bool
+		Gzip         bool
 		ListSources  bool
 		NoAlts       bool
 		NoColor      bool
 		NoRecursive  bool
 		Passive      bool
+		PerfReport   bool
+		Registrars   bool
+		ResolversDNS bool
+		RunDir       bool
 		Silent       bool
+		StrictSrcs   bool
+		TrailingDot  bool
 		Verbose      bool
 	}
 	Filepaths struct {
@@ -80,13 +112,19 @@ type enumArgs struct {
 		Directory        string
 		Domains          format.ParseStrings
 		ExcludedSrcs     string
+		ExcludeNames     format.ParseStrings
 		IncludedSrcs     string
 		JSONOutput       string
 		LogFile          string
 		Names            format.ParseStrings
+		PerfReportJSON   string
+		RunDir           string
 		Resolvers        format.ParseStrings
 		Trusted          format.ParseStrings
+		ScopeJSON        string
 		ScriptsDirectory string
+		SQLiteOut        string
+		Summary          string
 		TermOut          string
 	}
 }
@@ -97,33 +135,53 @@ func defineEnumArgumentFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(&args.ASNs, "asn", "ASNs separated by commas (can be used multiple times)")
 	enumFlags.Var(&args.CIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
 	enumFlags.Var(args.Blacklist, "bl", "Blacklist of subdomain names that will not be investigated")
+	enumFlags.IntVar(&args.ChunkSize, "chunk-size", 0, "Number of root domain names enumerated together in each chunk")
 	enumFlags.Var(args.BruteWordListMask, "wm", "\"hashcat-style\" wordlist masks for DNS brute forcing")
 	enumFlags.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	enumFlags.Var(args.Excluded, "exclude", "Data source names separated by commas to be excluded")
+	enumFlags.Var(args.Excluded, "exclude-sources", "Data source names separated by commas to be excluded (same as -exclude)")
+	enumFlags.IntVar(&args.FirstN, "first-n", 0, "Stop the enumeration after the first N names have been discovered")
+	enumFlags.Var(&args.Formats, "format", "Output formats separated by commas (txt, ndjson, gexf, d3, html, mermaid)")
 	enumFlags.Var(args.Included, "include", "Data source names separated by commas to be included")
+	enumFlags.Var(args.Included, "only-sources", "Data source names separated by commas to be the only ones included (same as -include)")
 	enumFlags.StringVar(&args.Interface, "iface", "", "Provide the network interface to send traffic through")
-	enumFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Deprecated flag to be replaced by dns-qps in version 4.0")
+	enumFlags.IntVar(&args.DNSBudget, "max-dns-queries", 0, "Total number of DNS queries issued before the enumeration winds down")
+	enumFlags.IntVar(&args.DNSBudget, "dns-budget", 0, "Alias of max-dns-queries")
 	enumFlags.IntVar(&args.MaxDNSQueries, "dns-qps", 0, "Maximum number of DNS queries per second across all resolvers")
 	enumFlags.IntVar(&args.ResolverQPS, "rqps", 0, "Maximum number of DNS queries per second for each untrusted resolver")
 	enumFlags.IntVar(&args.TrustedQPS, "trqps", 0, "Maximum number of DNS queries per second for each trusted resolver")
 	enumFlags.IntVar(&args.MaxDepth, "max-depth", 0, "Maximum number of subdomain labels for brute forcing")
 	enumFlags.IntVar(&args.MinForRecursive, "min-for-recursive", 1, "Subdomain labels seen before recursive brute forcing (Default: 1)")
+	enumFlags.Float64Var(&args.MinScore, "min-score", 0, "Minimum confidence score (0-1) of the names reported by data sources")
 	enumFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 80, 443)")
 	enumFlags.Var(args.Resolvers, "r", "IP addresses of untrusted DNS resolvers (can be used multiple times)")
 	enumFlags.Var(args.Resolvers, "tr", "IP addresses of trusted DNS resolvers (can be used multiple times)")
+	enumFlags.StringVar(&args.Retry, "retry", "", "Run ID of a prior enumeration whose names that failed to resolve are retried")
+	enumFlags.Var(&args.Tags, "tag", "Tags (key=value) recorded for this run separated by commas (can be used multiple times)")
+	enumFlags.StringVar(&args.TestSource, "test-source", "", "Query only this data source for the first domain and print the raw results")
 	enumFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
+	enumFlags.IntVar(&args.Workers, "workers", 1, "Number of domain chunks enumerated concurrently")
 }
 
 func defineEnumOptionFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.BoolVar(&args.Options.Active, "active", false, "Attempt zone transfers and certificate name grabs")
+	enumFlags.BoolVar(&args.Options.CertNames, "cert-names", false, "Resolve the in-scope names found in the certificates of the addresses (requires -active)")
 	enumFlags.BoolVar(&args.Options.BruteForcing, "brute", false, "Execute brute forcing after searches")
 	enumFlags.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	enumFlags.BoolVar(&args.Options.Registrars, "registrars", false, "Record the registrar of each root domain from RDAP (requires -active)")
+	enumFlags.BoolVar(&args.Options.FailFast, "fail-fast", false, "Abort the enumeration when the first domain name fails")
+	enumFlags.BoolVar(&args.Options.Gzip, "gzip", false, "Compress the text and NDJSON output files with gzip")
 	enumFlags.BoolVar(&args.Options.ListSources, "list", false, "Print the names of all available data sources")
 	enumFlags.BoolVar(&args.Options.Alterations, "alts", false, "Enable generation of altered names")
 	enumFlags.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
 	enumFlags.BoolVar(&args.Options.NoRecursive, "norecursive", false, "Turn off recursive brute forcing")
 	enumFlags.BoolVar(&args.Options.Passive, "passive", false, "Deprecated since passive is the default setting")
+	enumFlags.BoolVar(&args.Options.PerfReport, "perf-report", false, "Print a breakdown of where the time of the enumeration was spent")
+	enumFlags.BoolVar(&args.Options.ResolversDNS, "resolvers-from-dns", false, "Query the authoritative servers of each domain for the names within it")
+	enumFlags.BoolVar(&args.Options.RunDir, "run-dir", false, "Write the log and output files of this run to a timestamped subdirectory")
 	enumFlags.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	enumFlags.BoolVar(&args.Options.StrictSrcs, "strict-sources", false, "Abort the enumeration when a selected data source fails to start")
+	enumFlags.BoolVar(&args.Options.TrailingDot, "trailing-dot", false, "Output the names as fully-qualified with a trailing dot")
 	enumFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
 
@@ -136,12 +194,18 @@ func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the output files")
 	enumFlags.Var(&args.Filepaths.Domains, "df", "Path to a file providing root domain names")
 	enumFlags.StringVar(&args.Filepaths.ExcludedSrcs, "ef", "", "Path to a file providing data sources to exclude")
+	enumFlags.Var(&args.Filepaths.ExcludeNames, "exclude-file", "Path to a file providing names that are stored but not output")
 	enumFlags.StringVar(&args.Filepaths.IncludedSrcs, "if", "", "Path to a file providing data sources to include")
 	enumFlags.StringVar(&args.Filepaths.LogFile, "log", "", "Path to the log file where errors will be written")
 	enumFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
+	enumFlags.StringVar(&args.Filepaths.JSONOutput, "json-v4", "", "Path to the JSON Lines file in the legacy v4 shape (use - for stdout)")
+	enumFlags.StringVar(&args.Filepaths.PerfReportJSON, "perf-report-json", "", "Path to the JSON file containing the performance report")
 	enumFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing untrusted DNS resolvers")
 	enumFlags.Var(&args.Filepaths.Trusted, "trf", "Path to a file providing trusted DNS resolvers")
+	enumFlags.StringVar(&args.Filepaths.ScopeJSON, "scope-json", "", "Path to a JSON file providing an array of domains, IPs, CIDRs and ASNs in scope")
 	enumFlags.StringVar(&args.Filepaths.ScriptsDirectory, "scripts", "", "Path to a directory containing ADS scripts")
+	enumFlags.StringVar(&args.Filepaths.Summary, "summary", "", "Path to the JSON file containing the summary of the run")
+	enumFlags.StringVar(&args.Filepaths.SQLiteOut, "sqlite-out", "", "Path to a standalone SQLite file that will contain the results of this enumeration")
 	enumFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
 }
 
@@ -153,8 +217,36 @@ func runEnumCommand(clArgs []string) {
 	}
 	createOutputDirectory(cfg)
 
+	if args.TestSource != "" {
+		timeout := defaultSourceTestTimeout
+		if args.Timeout > 0 {
+			timeout = time.Duration(args.Timeout) * time.Minute
+		}
+		if err := testDataSource(cfg, args.TestSource, cfg.Domains()[0], timeout); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The run ID ties together the log file, the recorded run and the structured output of this enumeration
+	args.RunID = uuid.New().String()
+	fmt.Fprintf(color.Error, "%s %s\n", green("Run ID:"), yellow(args.RunID))
+
 	rLog, wLog := io.Pipe()
 	dir := config.OutputDirectory(cfg.Dir)
+	// The log and output files of the run are kept apart, while the graph database and the
+	// configuration files remain shared in the output directory
+	if args.Options.RunDir {
+		rundir, err := createRunDirectory(dir, time.Now())
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(color.Error, "%s %s\n", green("Run directory:"), yellow(rundir))
+		args.Filepaths.RunDir = rundir
+		dir = rundir
+	}
 	// Setup logging so that messages can be written to the file and used by the program
 	cfg.Log = log.New(wLog, "", log.Lmicroseconds)
 	logfile := filepath.Join(dir, "amass.log")
@@ -163,6 +255,7 @@ func runEnumCommand(clArgs []string) {
 	}
 	// Start handling the log messages
 	go writeLogsAndMessages(rLog, logfile, args.Options.Verbose)
+	cfg.Log.Printf("Run ID: %s", args.RunID)
 	// Create the System that will provide architecture to this enumeration
 	sys, err := systems.NewLocalSystem(cfg)
 	if err != nil {
@@ -170,11 +263,33 @@ func runEnumCommand(clArgs []string) {
 		os.Exit(1)
 	}
 	defer func() { _ = sys.Shutdown() }()
+	// The flag takes precedence over the dns_query_budget option applied by the system
+	if args.DNSBudget > 0 {
+		amassnet.SetDNSBudget(int64(args.DNSBudget))
+	}
 
-	if err := sys.SetDataSources(datasrcs.GetAllSources(sys)); err != nil {
+	all := datasrcs.GetAllSources(sys)
+	if unknown := unknownDataSources(cfg, all); len(unknown) > 0 {
+		r.Fprintf(color.Error, "The following data sources do not exist: %s\n", strings.Join(unknown, ", "))
+		if args.Options.StrictSrcs {
+			os.Exit(1)
+		}
+	}
+	// The data sources left out by the configuration are never started
+	srcs := datasrcs.SelectedDataSources(cfg, all)
+	if err := sys.SetDataSources(srcs); err != nil {
 		r.Fprintf(color.Error, "%v\n", err)
 		os.Exit(1)
 	}
+	if failed := failedDataSources(cfg, sys, srcs); len(failed) > 0 {
+		r.Fprintln(color.Error, "The following data sources failed to start:")
+		for _, line := range failed {
+			fmt.Fprintf(color.Error, "\t%s\n", line)
+		}
+		if args.Options.StrictSrcs {
+			os.Exit(1)
+		}
+	}
 
 	// Setup the new enumeration
 	e := enum.NewEnumeration(cfg, sys, sys.GraphDatabases()[0])
@@ -182,6 +297,11 @@ func runEnumCommand(clArgs []string) {
 		r.Fprintf(color.Error, "%s\n", "Failed to setup the enumeration")
 		os.Exit(1)
 	}
+	e.RunID = args.RunID
+	e.NamesOnly = args.Retry != ""
+	e.AuthoritativeResolvers = args.Options.ResolversDNS
+	e.CertNames = args.Options.CertNames
+	e.Registrars = args.Options.Registrars
 
 	var wg sync.WaitGroup
 	var outChans []chan string
@@ -196,6 +316,63 @@ func runEnumCommand(clArgs []string) {
 		outChans = append(outChans, printOutChan)
 	}
 
+	var acc *formatAccumulator
+	if len(args.Formats) > 0 {
+		prefix := filepath.Join(dir, "amass")
+		if args.Filepaths.AllFilePrefix != "" {
+			prefix = args.Filepaths.AllFilePrefix
+		}
+
+		acc, err = newFormatAccumulator(prefix, args.Formats, args.Options.Gzip)
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	hook, err := loadWebhookSink(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	buf, err := loadOutputBuffer(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	args.CDNRanges, err = loadCDNRanges(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	args.Checkpoint, err = loadCheckpointInterval(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	producer, err := loadKafkaSink(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	bucket, err := loadS3Sink(context.Background(), cfg, args.RunID)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// The assets extracted by the run are recorded, so they can later be selected by the run tags
+	args.RunAssets, err = newRunAssetsLog(cfg, args.RunID)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
 	wg.Add(1)
 	// This goroutine will handle saving the output to the text file
 	txtOutChan := make(chan string, 10)
@@ -211,8 +388,9 @@ func runEnumCommand(clArgs []string) {
 	}
 	defer cancel()
 
-	wg.Add(1)
-	go processOutput(ctx, sys.GraphDatabases()[0], e, outChans, done, &wg)
+	wg.Add(2)
+	go writeOutputSinks(e, buf, acc, hook, producer, bucket, outChans, &wg)
+	go processOutput(ctx, sys.GraphDatabases()[0], e, args, buf, done, &wg)
 	// Monitor for cancellation by the user
 	go func(d chan struct{}, c context.Context, f context.CancelFunc) {
 		quit := make(chan os.Signal, 1)
@@ -227,14 +405,107 @@ func runEnumCommand(clArgs []string) {
 		}
 	}(done, ctx, cancel)
 	// Start the enumeration process
-	if err := e.Start(ctx); err != nil {
-		r.Println(err)
-		os.Exit(1)
-	}
-	// Let all the output goroutines know that the enumeration has finished
+	statuses, enumErr := startEnumChunks(ctx, e, domainChunks(cfg.Domains(), args.ChunkSize), args.Workers, args.Options.FailFast)
+	// Let all the output goroutines know that the enumeration has finished. This also takes
+	// place when it failed, so the results are flushed and the run is recorded before exiting
 	close(done)
 	wg.Wait()
+	if err := args.RunAssets.Close(); err != nil {
+		r.Fprintf(color.Error, "Failed to write the run assets file: %v\n", err)
+	}
+	if dropped := buf.Dropped(); dropped > 0 {
+		r.Fprintf(color.Error, "%d results were dropped, since the output sinks fell behind the enumeration\n", dropped)
+		cfg.Log.Printf("The output buffer dropped %d results", dropped)
+	}
+	if bucket != nil {
+		// The upload is completed even when the enumeration was interrupted
+		if err := bucket.Close(context.Background()); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+		} else {
+			fmt.Fprintf(color.Error, "%s %s\n", green("The results were uploaded to"), yellow("s3://"+bucket.Bucket+"/"+bucket.Key))
+		}
+	}
+	if acc != nil {
+		if err := acc.Close(); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+		}
+	}
+	if args.Filepaths.SQLiteOut != "" {
+		if err := writeSQLiteResults(context.Background(), args.Filepaths.SQLiteOut, sys.GraphDatabases()[0], e); err != nil {
+			r.Fprintf(color.Error, "Failed to write the SQLite results file: %v\n", err)
+		}
+	}
+	if args.Filepaths.JSONOutput != "" {
+		if err := writeLegacyJSON(context.Background(), sys.GraphDatabases()[0], e, args.Filepaths.JSONOutput, args.Options.Gzip, args.Options.TrailingDot); err != nil {
+			r.Fprintf(color.Error, "Failed to write the legacy JSON output: %v\n", err)
+		}
+	}
+	if args.Options.PerfReport || args.Filepaths.PerfReportJSON != "" {
+		writePerfReport(e.PerfReport(), args.Options.PerfReport, args.Filepaths.PerfReportJSON)
+	}
+	if args.Filepaths.Summary != "" {
+		if err := writeRunSummary(context.Background(), sys.GraphDatabases()[0], e, args.RunID,
+			cfg.CollectionStartTime, time.Now(), args.Filepaths.Summary); err != nil {
+			r.Fprintf(color.Error, "Failed to write the run summary: %v\n", err)
+		}
+	}
+	// Record the run, so the names discovered can later be selected by the run tags
+	if err := recordRun(cfg, &runRecord{
+		ID:      args.RunID,
+		Start:   cfg.CollectionStartTime.UTC(),
+		End:     time.Now().UTC(),
+		Domains: cfg.Domains(),
+		Tags:    args.Tags,
+		Retry:   args.Retry,
+		Dir:     args.Filepaths.RunDir,
+	}); err != nil {
+		r.Fprintf(color.Error, "Failed to record the enumeration run: %v\n", err)
+	}
 	fmt.Fprintf(color.Error, "\n%s\n", green("The enumeration has finished"))
+	if amassnet.DNSBudgetExhausted() {
+		fmt.Fprintf(color.Error, "%s %s %s\n", green("The budget of"), yellow(strconv.FormatInt(amassnet.DNSQueries(), 10)),
+			green("DNS queries was exhausted before the enumeration completed"))
+	}
+	if len(statuses) > 1 || (enumErr != nil && len(statuses) > 0) {
+		printDomainStatuses(statuses)
+	}
+	if enumErr != nil {
+		if args.Options.FailFast || len(statuses) == 0 {
+			r.Println(enumErr)
+		}
+		os.Exit(1)
+	}
+}
+
+// failedDataSources returns a line for each data source selected by the configuration that failed to start.
+func failedDataSources(cfg *config.Config, sys systems.System, srcs []service.Service) []string {
+	failed := sys.FailedDataSources()
+
+	var lines []string
+	for _, src := range datasrcs.SelectedDataSources(cfg, srcs) {
+		if err, found := failed[src.String()]; found {
+			lines = append(lines, fmt.Sprintf("%s: %v", src.String(), err))
+		}
+	}
+	return lines
+}
+
+// unknownDataSources returns the names provided to select the data sources that do not match any of them.
+func unknownDataSources(cfg *config.Config, srcs []service.Service) []string {
+	available := stringset.New()
+	defer available.Close()
+
+	for _, src := range srcs {
+		available.Insert(src.String())
+	}
+
+	var unknown []string
+	for _, name := range cfg.SourceFilter.Sources {
+		if !available.Has(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
 }
 
 func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
@@ -246,6 +517,7 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		Blacklist:         stringset.New(),
 		Domains:           stringset.New(),
 		Excluded:          stringset.New(),
+		ExcludedNames:     stringset.New(),
 		Included:          stringset.New(),
 		Names:             stringset.New(),
 		Resolvers:         stringset.New(),
@@ -294,6 +566,13 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		color.Output = io.Discard
 		color.Error = io.Discard
 	}
+	// The flag was an alias of dns-qps in earlier versions, so the scripts still using it are warned
+	enumCommand.Visit(func(f *flag.Flag) {
+		if f.Name == "max-dns-queries" {
+			fmt.Fprintf(color.Error, "%s %s\n", yellow("Notice:"), green("The -max-dns-queries flag now sets the total "+
+				"number of DNS queries of the run, instead of the queries per second. Use -dns-qps to limit the rate"))
+		}
+	})
 	if args.AltWordListMask.Len() > 0 {
 		args.AltWordList.Union(args.AltWordListMask)
 	}
@@ -327,6 +606,13 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		r.Fprintf(color.Error, "Configuration error: %v\n", err)
 		os.Exit(1)
 	}
+
+	ext, err := loadScopeExtensions(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	args.OnlyInCIDRs = ext.OnlyInCIDRs
 	// Check if the user has requested the data source names
 	if args.Options.ListSources {
 		for _, line := range GetAllSourceInfo(cfg) {
@@ -334,15 +620,63 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		}
 		return nil, &args
 	}
+	if args.Retry != "" {
+		num, err := seedRetryNames(cfg, args.Retry)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the failed names: %v\n", err)
+			os.Exit(1)
+		}
+		if num == 0 {
+			r.Fprintf(color.Error, "No failed names within the current scope were recorded for the run %s\n", args.Retry)
+			return nil, &args
+		}
+		fmt.Fprintf(color.Error, "%s %s %s %s\n", green("Retrying"), yellow(strconv.Itoa(num)),
+			green("names that failed during the run"), yellow(args.Retry))
+	}
+	// Report the problems that would otherwise surface once the system starts
+	if err := systems.ValidateConfig(context.Background(), cfg); err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
 	// Some input validation
 	if !cfg.Active && len(args.Ports) > 0 {
 		r.Fprintln(color.Error, "Ports can only be scanned in the active mode")
 		os.Exit(1)
 	}
+	if !cfg.Active && args.Options.CertNames {
+		r.Fprintln(color.Error, "Certificates can only be pulled in the active mode")
+		os.Exit(1)
+	}
+	if !cfg.Active && args.Options.Registrars {
+		r.Fprintln(color.Error, "Registrars can only be looked up in the active mode")
+		os.Exit(1)
+	}
 	if len(cfg.Domains()) == 0 {
 		r.Fprintln(color.Error, "Configuration error: No root domain names were provided")
 		os.Exit(1)
 	}
+	if args.MinScore < 0 || args.MinScore > 1 {
+		r.Fprintln(color.Error, "The min-score must be between 0 and 1")
+		os.Exit(1)
+	}
+	if args.FirstN < 0 {
+		r.Fprintln(color.Error, "The first-n value must be a positive number")
+		os.Exit(1)
+	}
+	if args.ChunkSize < 0 || args.Workers < 1 {
+		r.Fprintln(color.Error, "The chunk-size must be positive and at least one worker is required")
+		os.Exit(1)
+	}
+	for _, f := range args.Formats {
+		if !validOutputFormat(f) {
+			r.Fprintf(color.Error, "%s is not a supported output format\n", f)
+			os.Exit(1)
+		}
+	}
+	if _, err := enum.BlacklistPatterns(cfg); err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
 	return cfg, &args
 }
 
@@ -365,6 +699,9 @@ func saveTextOutput(e *enum.Enumeration, args *enumArgs, output chan string, wg
 	defer wg.Done()
 
 	dir := config.OutputDirectory(e.Config.Dir)
+	if args.Filepaths.RunDir != "" {
+		dir = args.Filepaths.RunDir
+	}
 	txtfile := filepath.Join(dir, "amass.txt")
 	if args.Filepaths.TermOut != "" {
 		txtfile = args.Filepaths.TermOut
@@ -376,18 +713,16 @@ func saveTextOutput(e *enum.Enumeration, args *enumArgs, output chan string, wg
 		return
 	}
 
-	outptr, err := os.OpenFile(txtfile, os.O_WRONLY|os.O_CREATE, 0644)
+	outptr, err := createOutputFile(txtfile, args.Options.Gzip)
 	if err != nil {
 		r.Fprintf(color.Error, "Failed to open the text output file: %v\n", err)
 		os.Exit(1)
 	}
 	defer func() {
-		_ = outptr.Sync()
-		_ = outptr.Close()
+		if err := outptr.Close(); err != nil {
+			r.Fprintf(color.Error, "Failed to write the text output file: %v\n", err)
+		}
 	}()
-
-	_ = outptr.Truncate(0)
-	_, _ = outptr.Seek(0, 0)
 	// Save all the output returned by the enumeration
 	for out := range output {
 		// Write the line to the output file
@@ -395,32 +730,96 @@ func saveTextOutput(e *enum.Enumeration, args *enumArgs, output chan string, wg
 	}
 }
 
-func processOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, outputs []chan string, done chan struct{}, wg *sync.WaitGroup) {
+func processOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, args *enumArgs, buf *outputBuffer, done chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
-	defer func() {
-		// Signal all the other output goroutines to terminate
-		for _, ch := range outputs {
-			close(ch)
-		}
-	}()
+	// Let the output sinks know that no more results will be extracted
+	defer buf.Close()
 
 	// This filter ensures that we only get new names
 	known := stringset.New()
 	defer known.Close()
+	// The names emitted so far, used to honor the first-n option
+	names := stringset.New()
+	defer names.Close()
+	// The assets output so far, reported by the checkpoint summaries
+	counts := newCheckpointCounts()
+	defer counts.Close()
+	// The names found to resolve within the scope.only_in_cidrs ranges
+	inCIDRs := stringset.New()
+	defer inCIDRs.Close()
 	// The function that obtains output from the enum and puts it on the channel
 	extract := func(since time.Time) {
-		for _, o := range NewOutput(ctx, g, e, known, since) {
-			for _, ch := range outputs {
-				ch <- o
+		for _, rel := range NewRelations(ctx, g, e, known, since) {
+			// The assets seen by the run are recorded, even when the relation is not output
+			args.RunAssets.Add(rel)
+			if rel.Score != nil && *rel.Score < args.MinScore {
+				continue
+			}
+			// Names in the exclude files are still stored, but not output
+			if (rel.FromType == "FQDN" && args.ExcludedNames.Has(rel.From)) ||
+				(rel.ToType == "FQDN" && args.ExcludedNames.Has(rel.To)) {
+				continue
 			}
+			if len(args.OnlyInCIDRs) > 0 && !relationInCIDRs(ctx, g, rel, args.OnlyInCIDRs, inCIDRs) {
+				continue
+			}
+			if args.FirstN > 0 && rel.FromType == "FQDN" && !names.Has(rel.From) {
+				if names.Len() >= args.FirstN {
+					continue
+				}
+				names.Insert(rel.From)
+				if names.Len() == args.FirstN {
+					fmt.Fprintf(color.Error, "%s %s %s\n", green("Stopping the enumeration after the first"),
+						yellow(strconv.Itoa(args.FirstN)), green("names"))
+					// The names already discovered are still stored, and the final extraction
+					// and summary take place once the enumeration returns
+					e.Stop()
+				}
+			}
+			if args.Options.TrailingDot {
+				rel = rel.withTrailingDot()
+			}
+			rel.RunID = args.RunID
+			if rel.ToType == "IPAddress" {
+				rel.CDN = cdnProvider(args.CDNRanges, net.ParseIP(rel.To))
+			}
+			counts.Add(rel)
+			buf.Put(rel)
 		}
 	}
 
-	t := time.NewTimer(10 * time.Second)
+	var checkpoint <-chan time.Time
+	if args.Checkpoint > 0 {
+		ticker := time.NewTicker(args.Checkpoint)
+		defer ticker.Stop()
+		checkpoint = ticker.C
+	}
+	// The verbose output includes the live counts of the running enumeration
+	var progress <-chan time.Time
+	if args.Options.Verbose {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		progress = ticker.C
+	}
+
+	// The results are extracted more often when sampling the first names, so the enumeration
+	// is stopped soon after they have been discovered
+	interval := 10 * time.Second
+	if args.FirstN > 0 {
+		interval = time.Second
+	}
+
+	t := time.NewTimer(interval)
 	defer t.Stop()
 	last := e.Config.CollectionStartTime
 	for {
 		select {
+		case <-checkpoint:
+			counts.Print(e.Config.Log, e.Config.CollectionStartTime)
+		case <-progress:
+			p := e.Progress()
+			format.FprintProgress(color.Error, time.Since(e.Config.CollectionStartTime),
+				p.Names, p.DNSQueries, p.ActiveSources, p.Queued)
 		case <-ctx.Done():
 			extract(last)
 			return
@@ -430,12 +829,97 @@ func processOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, ou
 		case <-t.C:
 			next := time.Now()
 			extract(last)
-			t.Reset(10 * time.Second)
+			t.Reset(interval)
 			last = next
 		}
 	}
 }
 
+// relationInCIDRs returns true when the relation is kept by the scope.only_in_cidrs ranges. The relations
+// leading to addresses require the address to be within the ranges, while the other relations of a name
+// require the name to resolve to an address within the ranges. The names found to qualify are cached.
+func relationInCIDRs(ctx context.Context, g *netmap.Graph, rel *assetRelation, cidrs []*net.IPNet, cache *stringset.Set) bool {
+	if rel.ToType == "IPAddress" {
+		ip := net.ParseIP(rel.To)
+		return ip != nil && len(format.AddrsInCIDRs([]requests.AddressInfo{{Address: ip}}, cidrs)) > 0
+	}
+	if rel.FromType != "FQDN" {
+		return true
+	}
+	if cache.Has(rel.From) {
+		return true
+	}
+
+	pairs, err := g.NamesToAddrs(ctx, time.Time{}, rel.From)
+	if err != nil {
+		return false
+	}
+	for _, p := range pairs {
+		ip := net.ParseIP(p.Addr.Address.String())
+		if ip != nil && len(format.AddrsInCIDRs([]requests.AddressInfo{{Address: ip}}, cidrs)) > 0 {
+			cache.Insert(rel.From)
+			return true
+		}
+	}
+	return false
+}
+
+// writeOutputSinks hands the results taken from the buffer to each of the output sinks. The webhook and
+// Kafka receive the results in batches holding what was available in the buffer, up to maxWebhookBatch.
+func writeOutputSinks(e *enum.Enumeration, buf *outputBuffer, acc *formatAccumulator, hook *webhookSink, producer *kafkaSink, bucket *s3Sink, outputs []chan string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer func() {
+		// Signal all the other output goroutines to terminate
+		for _, ch := range outputs {
+			close(ch)
+		}
+	}()
+
+	var batch []*assetRelation
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// The enumeration context may already be done when the buffer is drained
+		if hook != nil {
+			if err := hook.Send(context.Background(), batch); err != nil {
+				e.Config.Log.Printf("Failed to send the results to the webhook: %v", err)
+			}
+		}
+		if producer != nil {
+			if err := producer.Send(context.Background(), batch); err != nil {
+				e.Config.Log.Printf("Failed to send the results to Kafka: %v", err)
+			}
+		}
+		batch = nil
+	}
+	defer send()
+
+	results := buf.Results()
+	for rel := range results {
+		if acc != nil {
+			acc.Add(rel)
+		}
+		if bucket != nil {
+			if err := bucket.Write(context.Background(), rel); err != nil {
+				e.Config.Log.Printf("Failed to send the results to S3: %v", err)
+			}
+		}
+
+		line := rel.String()
+		for _, ch := range outputs {
+			ch <- line
+		}
+
+		if hook != nil || producer != nil {
+			batch = append(batch, rel)
+			if len(results) == 0 || len(batch) >= maxWebhookBatch {
+				send()
+			}
+		}
+	}
+}
+
 func writeLogsAndMessages(logs *io.PipeReader, logfile string, verbose bool) {
 	wildcard := regexp.MustCompile("DNS wildcard")
 	queries := regexp.MustCompile("Querying")
@@ -548,8 +1032,27 @@ func processEnumInputFiles(args *enumArgs) error {
 			args.Names.InsertMany(list...)
 		}
 	}
+	if len(args.Filepaths.ExcludeNames) > 0 {
+		for _, f := range args.Filepaths.ExcludeNames {
+			list, err := config.GetListFromFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to parse the excluded names file: %v", err)
+			}
+			for _, name := range list {
+				args.ExcludedNames.Insert(strings.ToLower(strings.TrimSpace(name)))
+			}
+		}
+	}
+	// The root domain names are read from stdin, one per line, when "-" is provided to -d or -df
+	stdin := args.Domains.Has("-")
+	args.Domains.Remove("-")
 	if len(args.Filepaths.Domains) > 0 {
 		for _, f := range args.Filepaths.Domains {
+			if f == "-" {
+				stdin = true
+				continue
+			}
+
 			list, err := config.GetListFromFile(f)
 			if err != nil {
 				return fmt.Errorf("failed to parse the domain names file: %v", err)
@@ -557,6 +1060,13 @@ func processEnumInputFiles(args *enumArgs) error {
 			args.Domains.InsertMany(list...)
 		}
 	}
+	if stdin {
+		list, err := getWordList(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read the domain names from stdin: %v", err)
+		}
+		args.Domains.InsertMany(list...)
+	}
 	if len(args.Filepaths.Resolvers) > 0 {
 		for _, f := range args.Filepaths.Resolvers {
 			list, err := config.GetListFromFile(f)
@@ -566,6 +1076,16 @@ func processEnumInputFiles(args *enumArgs) error {
 			args.Resolvers.InsertMany(list...)
 		}
 	}
+	if args.Filepaths.ScopeJSON != "" {
+		scope, err := parseScopeJSON(args.Filepaths.ScopeJSON)
+		if err != nil {
+			return err
+		}
+		args.Domains.InsertMany(scope.Domains...)
+		args.Addresses = append(args.Addresses, scope.Addresses...)
+		args.CIDRs = append(args.CIDRs, scope.CIDRs...)
+		args.ASNs = append(args.ASNs, scope.ASNs...)
+	}
 	return nil
 }
 
@@ -638,6 +1158,13 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = e.MaxDNSQueries
 	}
+	if e.Included.Len() > 0 {
+		conf.SourceFilter.Include = true
+		conf.SourceFilter.Sources = e.Included.Slice()
+	} else if e.Excluded.Len() > 0 {
+		conf.SourceFilter.Include = false
+		conf.SourceFilter.Sources = e.Excluded.Slice()
+	}
 	// Attempt to add the provided domains to the configuration
 	conf.AddDomains(e.Domains.Slice()...)
 	return nil
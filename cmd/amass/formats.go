@@ -0,0 +1,468 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The output formats supported by the enum format flag.
+const (
+	formatText    = "txt"
+	formatNDJSON  = "ndjson"
+	formatGEXF    = "gexf"
+	formatD3      = "d3"
+	formatHTML    = "html"
+	formatMermaid = "mermaid"
+)
+
+var supportedFormats = []string{formatText, formatNDJSON, formatGEXF, formatD3, formatHTML, formatMermaid}
+
+func validOutputFormat(f string) bool {
+	for _, s := range supportedFormats {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFormat returns true when the format is among those requested.
+func hasFormat(formats []string, f string) bool {
+	for _, requested := range formats {
+		if requested == f {
+			return true
+		}
+	}
+	return false
+}
+
+type formatNode struct {
+	ID    int       `json:"id"`
+	Label string    `json:"label"`
+	Type  string    `json:"type"`
+	Start time.Time `json:"-"`
+	End   time.Time `json:"-"`
+	// An open interval is kept once a relation of the node has no time
+	openStart bool
+	openEnd   bool
+}
+
+type formatEdge struct {
+	Source int       `json:"source"`
+	Target int       `json:"target"`
+	Label  string    `json:"label"`
+	Start  time.Time `json:"-"`
+	End    time.Time `json:"-"`
+}
+
+// formatAccumulator builds the nodes and edges of the enumeration results as they are discovered,
+// so that all the requested formats can be written from the single live run.
+type formatAccumulator struct {
+	prefix  string
+	formats map[string]bool
+	ndjson  *outputFile
+	ids     map[string]int
+	nodes   []*formatNode
+	edges   []*formatEdge
+	// The GEXF graph carries the time interval of each node and edge when dynamic is true
+	dynamic bool
+	// The HTML page loads the D3 library from its own directory when relative is true
+	relative bool
+}
+
+// newFormatAccumulator returns an accumulator writing the formats to files named with the prefix.
+// The NDJSON file is compressed with gzip when requested.
+func newFormatAccumulator(prefix string, formats []string, compress bool) (*formatAccumulator, error) {
+	acc := &formatAccumulator{
+		prefix:  prefix,
+		formats: make(map[string]bool),
+		ids:     make(map[string]int),
+	}
+
+	for _, f := range formats {
+		acc.formats[f] = true
+	}
+
+	if acc.formats[formatNDJSON] {
+		f, err := createOutputFile(prefix+".ndjson", compress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the NDJSON output file: %v", err)
+		}
+		acc.ndjson = f
+	}
+	return acc, nil
+}
+
+// Add inserts the relationship into the accumulated graph and streams it to the NDJSON file.
+func (acc *formatAccumulator) Add(rel *assetRelation) {
+	if acc.ndjson != nil {
+		if data, err := json.Marshal(rel); err == nil {
+			_, _ = acc.ndjson.Write(append(data, '\n'))
+		}
+	}
+
+	edge := &formatEdge{
+		Source: acc.node(rel.FromID, rel.From, rel.FromType),
+		Target: acc.node(rel.ToID, rel.To, rel.ToType),
+		Label:  rel.Relation,
+	}
+	if rel.CreatedAt != nil {
+		edge.Start = *rel.CreatedAt
+	}
+	if rel.LastSeen != nil {
+		edge.End = *rel.LastSeen
+	}
+	acc.edges = append(acc.edges, edge)
+
+	// The nodes exist from their earliest relation until the last time any of their relations was seen
+	for _, idx := range []int{edge.Source, edge.Target} {
+		n := acc.nodes[idx]
+
+		if edge.Start.IsZero() {
+			n.openStart, n.Start = true, time.Time{}
+		} else if !n.openStart && (n.Start.IsZero() || edge.Start.Before(n.Start)) {
+			n.Start = edge.Start
+		}
+		if edge.End.IsZero() {
+			n.openEnd, n.End = true, time.Time{}
+		} else if !n.openEnd && edge.End.After(n.End) {
+			n.End = edge.End
+		}
+	}
+}
+
+func (acc *formatAccumulator) node(id, label, ntype string) int {
+	if idx, found := acc.ids[id]; found {
+		return idx
+	}
+
+	idx := len(acc.nodes)
+	acc.ids[id] = idx
+	acc.nodes = append(acc.nodes, &formatNode{
+		ID:    idx,
+		Label: label,
+		Type:  ntype,
+	})
+	return idx
+}
+
+// Close writes the formats that require the complete graph and closes the NDJSON file.
+func (acc *formatAccumulator) Close() error {
+	var errs []string
+
+	if acc.ndjson != nil {
+		if err := acc.ndjson.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if acc.formats[formatGEXF] {
+		if err := acc.writeGEXF(acc.prefix + ".gexf"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	// The HTML page loads the graph from the D3 JSON file
+	if acc.formats[formatD3] || acc.formats[formatHTML] {
+		if err := acc.writeD3(acc.prefix + "_d3.json"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if acc.formats[formatMermaid] {
+		if err := acc.writeMermaid(acc.prefix + ".mmd"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if acc.formats[formatHTML] {
+		script := d3ScriptURL
+		if acc.relative {
+			script = d3ScriptFile
+		}
+		if err := writeHTML(acc.prefix+".html", filepath.Base(acc.prefix)+"_d3.json", script); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to write the output formats: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// WriteFormat writes the accumulated graph, followed by a newline, in one of the formats contained in a single document.
+func (acc *formatAccumulator) WriteFormat(w io.Writer, f string) error {
+	var data []byte
+	var err error
+
+	switch f {
+	case formatD3:
+		data, err = acc.d3Data()
+	case formatGEXF:
+		data, err = acc.gexfData()
+	case formatMermaid:
+		data = acc.mermaidData()
+	default:
+		return fmt.Errorf("%s cannot be written as a single document", f)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func (acc *formatAccumulator) writeD3(path string) error {
+	data, err := acc.d3Data()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (acc *formatAccumulator) d3Data() ([]byte, error) {
+	return json.Marshal(struct {
+		Nodes []*formatNode `json:"nodes"`
+		Links []*formatEdge `json:"links"`
+	}{
+		Nodes: acc.nodes,
+		Links: acc.edges,
+	})
+}
+
+// The D3 library loaded by the HTML page, from the CDN or from the directory of the page.
+const (
+	d3ScriptURL  = "https://d3js.org/d3.v7.min.js"
+	d3ScriptFile = "d3.v7.min.js"
+)
+
+// The page references the D3 JSON file by a relative path, so the files can be served from any directory.
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>OWASP Amass Network Mapping</title>
+<style>
+body { margin: 0; background: #ffffff; }
+.links line { stroke: #999999; stroke-opacity: 0.6; }
+.nodes circle { stroke: #ffffff; stroke-width: 1.5px; }
+</style>
+</head>
+<body>
+<svg width="100%" height="100%"></svg>
+<script src="{{.Script}}"></script>
+<script>
+d3.json({{.Data}}).then(function(graph) {
+  var width = window.innerWidth, height = window.innerHeight;
+  var color = d3.scaleOrdinal(d3.schemeCategory10);
+  var svg = d3.select("svg").attr("width", width).attr("height", height);
+
+  var simulation = d3.forceSimulation(graph.nodes)
+    .force("link", d3.forceLink(graph.links).id(function(d) { return d.id; }))
+    .force("charge", d3.forceManyBody().strength(-40))
+    .force("center", d3.forceCenter(width / 2, height / 2));
+
+  var link = svg.append("g").attr("class", "links").selectAll("line")
+    .data(graph.links).enter().append("line");
+
+  var node = svg.append("g").attr("class", "nodes").selectAll("circle")
+    .data(graph.nodes).enter().append("circle")
+    .attr("r", 5)
+    .attr("fill", function(d) { return color(d.type); });
+
+  node.append("title").text(function(d) { return d.label + " (" + d.type + ")"; });
+
+  simulation.on("tick", function() {
+    link.attr("x1", function(d) { return d.source.x; })
+      .attr("y1", function(d) { return d.source.y; })
+      .attr("x2", function(d) { return d.target.x; })
+      .attr("y2", function(d) { return d.target.y; });
+    node.attr("cx", function(d) { return d.x; })
+      .attr("cy", function(d) { return d.y; });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// writeHTML writes the page loading the graph from the data file and the D3 library from the script path.
+func writeHTML(path, data, script string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return htmlTemplate.Execute(f, struct {
+		Data   string
+		Script string
+	}{
+		Data:   data,
+		Script: script,
+	})
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	Start     string         `xml:"start,attr,omitempty"`
+	End       string         `xml:"end,attr,omitempty"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Label  string `xml:"label,attr"`
+	Start  string `xml:"start,attr,omitempty"`
+	End    string `xml:"end,attr,omitempty"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfGraph struct {
+	Mode            string         `xml:"mode,attr"`
+	TimeFormat      string         `xml:"timeformat,attr,omitempty"`
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Creator string    `xml:"meta>creator"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+func (acc *formatAccumulator) writeGEXF(path string) error {
+	data, err := acc.gexfData()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (acc *formatAccumulator) gexfData() ([]byte, error) {
+	doc := gexf{
+		XMLNS:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Creator: "OWASP Amass - https://github.com/owasp-amass/amass",
+		Graph: gexfGraph{
+			Mode:            "static",
+			DefaultEdgeType: "directed",
+			Attributes: gexfAttributes{
+				Class:      "node",
+				Attributes: []gexfAttribute{{ID: "0", Title: "type", Type: "string"}},
+			},
+		},
+	}
+
+	if acc.dynamic {
+		doc.Graph.Mode = "dynamic"
+		doc.Graph.TimeFormat = "dateTime"
+	}
+
+	for _, n := range acc.nodes {
+		node := gexfNode{
+			ID:        strconv.Itoa(n.ID),
+			Label:     n.Label,
+			AttValues: []gexfAttValue{{For: "0", Value: n.Type}},
+		}
+		if acc.dynamic {
+			node.Start, node.End = gexfTime(n.Start), gexfTime(n.End)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range acc.edges {
+		edge := gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: strconv.Itoa(e.Source),
+			Target: strconv.Itoa(e.Target),
+			Label:  e.Label,
+		}
+		if acc.dynamic {
+			edge.Start, edge.End = gexfTime(e.Start), gexfTime(e.End)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// gexfTime returns the time in the dateTime format of the dynamic graph, or an empty string
+// for the zero time, which leaves the interval open.
+func gexfTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// The Mermaid node shapes used for each asset type, as the opening and closing delimiters of the label.
+var mermaidShapes = map[string][2]string{
+	"FQDN":            {"(", ")"},
+	"IPAddress":       {"[", "]"},
+	"Netblock":        {"[[", "]]"},
+	"ASN":             {"{{", "}}"},
+	"RIROrganization": {"([", "])"},
+}
+
+func (acc *formatAccumulator) writeMermaid(path string) error {
+	return os.WriteFile(path, acc.mermaidData(), 0644)
+}
+
+// mermaidData returns the graph as a Mermaid flowchart definition, which renders in GitHub markdown.
+func (acc *formatAccumulator) mermaidData() []byte {
+	var b strings.Builder
+
+	b.WriteString("graph LR\n")
+	for _, n := range acc.nodes {
+		shape, found := mermaidShapes[n.Type]
+		if !found {
+			shape = [2]string{"[", "]"}
+		}
+		fmt.Fprintf(&b, "    n%d%s\"%s\"%s\n", n.ID, shape[0], mermaidEscape(n.Label), shape[1])
+	}
+	for _, e := range acc.edges {
+		fmt.Fprintf(&b, "    n%d -->|\"%s\"| n%d\n", e.Source, mermaidEscape(e.Label), e.Target)
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// mermaidEscape replaces the characters that would end a quoted Mermaid label with entity codes.
+func mermaidEscape(label string) string {
+	return strings.NewReplacer("\"", "#quot;", "\n", " ").Replace(label)
+}
@@ -23,6 +23,18 @@ func runHelpCommand(clArgs []string) {
 		runEnumCommand(help)
 	case "intel":
 		runIntelCommand(help)
+	case "subs":
+		runSubsCommand(help)
+	case "db":
+		runDBCommand(help)
+	case "viz":
+		runVizCommand(help)
+	case "config":
+		runConfigCommand(help)
+	case "track":
+		runTrackCommand(help)
+	case "asn":
+		runASNCommand(help)
 	default:
 		commandUsage(mainUsageMsg, helpCommand, helpBuf)
 		return
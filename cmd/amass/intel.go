@@ -35,6 +35,7 @@ type intelArgs struct {
 	Addresses        format.ParseIPs
 	ASNs             format.ParseASNs
 	CIDRs            format.ParseCIDRs
+	CertConcurrency  int
 	OrganizationName string
 	Domains          *stringset.Set
 	Excluded         *stringset.Set
@@ -69,6 +70,7 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.Var(&args.Addresses, "addr", "IPs and ranges (192.168.1.1-254) separated by commas")
 	intelFlags.Var(&args.ASNs, "asn", "ASNs separated by commas (can be used multiple times)")
 	intelFlags.Var(&args.CIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
+	intelFlags.IntVar(&args.CertConcurrency, "cert-concurrency", 0, "Number of addresses having their certificates pulled at once (default: 50)")
 	intelFlags.StringVar(&args.OrganizationName, "org", "", "Search string provided against AS description information")
 	intelFlags.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	intelFlags.Var(args.Excluded, "exclude", "Data source names separated by commas to be excluded")
@@ -220,6 +222,21 @@ func runIntelCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
+	ic.CertConcurrency, err = intel.CertConcurrency(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	// The flag takes precedence over the cert_concurrency option
+	if args.CertConcurrency > 0 {
+		ic.CertConcurrency = args.CertConcurrency
+	}
+	ic.CertTimeout, err = intel.CertTimeout(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
 	if args.Options.ReverseWhois {
 		if len(ic.Config.Domains()) == 0 {
 			r.Fprintln(color.Error, "No root domain names were provided")
@@ -8,22 +8,81 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/netip"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/caffix/netmap"
 	"github.com/caffix/stringset"
 	"github.com/owasp-amass/amass/v4/enum"
+	"github.com/owasp-amass/amass/v4/format"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/asset-db/types"
 	oam "github.com/owasp-amass/open-asset-model"
 	"github.com/owasp-amass/open-asset-model/domain"
 	"github.com/owasp-amass/open-asset-model/network"
-	"golang.org/x/net/publicsuffix"
 )
 
-func NewOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, filter *stringset.Set, since time.Time) []string {
-	var output []string
+// assetRelation is a relationship between two assets discovered during an enumeration.
+// The fields shared with the other JSON output of the command-line tools are embedded.
+type assetRelation struct {
+	format.RelationRecord
+	FromID string `json:"-"`
+	ToID   string `json:"-"`
+	// The confidence score of the FQDN the relation originates from
+	Score *float64 `json:"score,omitempty"`
+	// The label of the bruteforce labeled wordlist that provided the FQDN
+	Wordlist string `json:"wordlist,omitempty"`
+	// The identifier of the enumeration run that extracted the relation
+	RunID string `json:"run_id,omitempty"`
+	// The CDN or shared hosting provider of the IP address the relation leads to
+	CDN string `json:"cdn,omitempty"`
+	// The times the relation was first and last seen in the graph database
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
+
+func (r *assetRelation) String() string {
+	arrow := white("-->")
+	from := green(r.From) + blue(" ("+r.FromType+")")
+	to := green(r.To) + blue(" ("+r.ToType+")")
+
+	line := fmt.Sprintf("%s %s %s %s %s", from, arrow, magenta(r.Relation), arrow, to)
+	if r.Wordlist != "" {
+		line += yellow(" [wordlist: " + r.Wordlist + "]")
+	}
+	if r.CDN != "" {
+		line += yellow(" [cdn: " + r.CDN + "]")
+	}
+	return line
+}
+
+// withTrailingDot returns a copy of the relation with the FQDNs in fully-qualified form.
+// The names stored in the graph database are not changed.
+func (r *assetRelation) withTrailingDot() *assetRelation {
+	c := *r
+	if c.FromType == "FQDN" {
+		c.From = fqdnWithTrailingDot(c.From)
+	}
+	if c.ToType == "FQDN" {
+		c.To = fqdnWithTrailingDot(c.To)
+	}
+	return &c
+}
+
+func fqdnWithTrailingDot(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// NewRelations returns the relationships between assets discovered by the enumeration since the provided time.
+// The filter is updated by NewRelations.
+func NewRelations(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, filter *stringset.Set, since time.Time) []*assetRelation {
+	var output []*assetRelation
 
 	// Make sure a filter has been created
 	if filter == nil {
@@ -38,10 +97,9 @@ func NewOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, filter
 		}
 	}
 
-	arrow := white("-->")
 	start := e.Config.CollectionStartTime.UTC()
 	for _, from := range assets {
-		fromstr := extractAssetName(from)
+		fromstr, fromtype := assetNameAndType(from)
 
 		if rels, err := g.DB.OutgoingRelations(from, start); err == nil {
 			for _, rel := range rels {
@@ -50,9 +108,37 @@ func NewOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, filter
 					continue
 				}
 				if to, err := g.DB.FindById(rel.ToAsset.ID, start); err == nil {
-					tostr := extractAssetName(to)
-
-					output = append(output, fmt.Sprintf("%s %s %s %s %s", fromstr, arrow, magenta(rel.Type), arrow, tostr))
+					tostr, totype := assetNameAndType(to)
+
+					r := &assetRelation{
+						RelationRecord: format.RelationRecord{
+							From:     fromstr,
+							FromType: fromtype,
+							Relation: rel.Type,
+							To:       tostr,
+							ToType:   totype,
+						},
+						FromID: from.ID,
+						ToID:   to.ID,
+					}
+					if !rel.CreatedAt.IsZero() {
+						created := rel.CreatedAt.UTC()
+						r.CreatedAt = &created
+					}
+					if !rel.LastSeen.IsZero() {
+						seen := rel.LastSeen.UTC()
+						r.LastSeen = &seen
+					}
+					if fromtype == "FQDN" {
+						if score, ok := e.Confidence(fromstr); ok {
+							r.Score = &score
+						}
+						if label, ok := e.WordlistLabel(fromstr); ok {
+							r.Wordlist = label
+						}
+					}
+
+					output = append(output, r)
 					filter.Insert(lineid)
 				}
 			}
@@ -62,33 +148,31 @@ func NewOutput(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, filter
 	return output
 }
 
-func extractAssetName(a *types.Asset) string {
-	var result string
-
+func assetNameAndType(a *types.Asset) (string, string) {
 	switch a.Asset.AssetType() {
 	case oam.FQDN:
 		if fqdn, ok := a.Asset.(domain.FQDN); ok {
-			result = green(fqdn.Name) + blue(" (FQDN)")
+			return fqdn.Name, "FQDN"
 		}
 	case oam.IPAddress:
 		if ip, ok := a.Asset.(network.IPAddress); ok {
-			result = green(ip.Address.String()) + blue(" (IPAddress)")
+			return ip.Address.String(), "IPAddress"
 		}
 	case oam.ASN:
 		if asn, ok := a.Asset.(network.AutonomousSystem); ok {
-			result = green(strconv.Itoa(asn.Number)) + blue(" (ASN)")
+			return strconv.Itoa(asn.Number), "ASN"
 		}
 	case oam.RIROrg:
 		if rir, ok := a.Asset.(network.RIROrganization); ok {
-			result = green(rir.RIRId+rir.Name) + blue(" (RIROrganization)")
+			return rir.RIRId + rir.Name, "RIROrganization"
 		}
 	case oam.Netblock:
 		if nb, ok := a.Asset.(network.Netblock); ok {
-			result = green(nb.Cidr.String()) + blue(" (Netblock)")
+			return nb.Cidr.String(), "Netblock"
 		}
 	}
 
-	return result
+	return "", ""
 }
 
 // ExtractOutput is a convenience method for obtaining new discoveries made by the enumeration process.
@@ -136,7 +220,7 @@ func EventOutput(ctx context.Context, g *netmap.Graph, domains []string, since t
 
 	lookup := make(outLookup, len(names))
 	for _, n := range names {
-		d, err := publicsuffix.EffectiveTLDPlusOne(n)
+		d, err := amassdns.EffectiveTLDPlusOne(n)
 		if err != nil {
 			continue
 		}
@@ -168,6 +252,97 @@ func EventOutput(ctx context.Context, g *netmap.Graph, domains []string, since t
 	return addInfrastructureInfo(lookup, f, cache)
 }
 
+// AddrOutput returns the names within the receiver Graph that resolve to the provided addresses
+// or to addresses within the provided CIDRs.
+func AddrOutput(ctx context.Context, g *netmap.Graph, addrs []net.IP, cidrs []*net.IPNet, asninfo bool, cache *requests.ASNCache) []*requests.Output {
+	assets := make(map[string]*types.Asset)
+
+	for _, addr := range addrs {
+		ip, ok := netip.AddrFromSlice(addr)
+		if !ok {
+			continue
+		}
+
+		ip = ip.Unmap()
+		t := "IPv4"
+		if ip.Is6() {
+			t = "IPv6"
+		}
+
+		if found, err := g.DB.FindByContent(network.IPAddress{Address: ip, Type: t}, time.Time{}); err == nil {
+			for _, a := range found {
+				assets[a.ID] = a
+			}
+		}
+	}
+	if len(cidrs) > 0 {
+		if all, err := g.DB.FindByType(oam.IPAddress, time.Time{}); err == nil {
+			for _, a := range all {
+				if ip, ok := a.Asset.(network.IPAddress); ok && cidrsContain(cidrs, net.IP(ip.Address.AsSlice())) {
+					assets[a.ID] = a
+				}
+			}
+		}
+	}
+
+	lookup := make(outLookup)
+	for _, asset := range assets {
+		ip, ok := asset.Asset.(network.IPAddress)
+		if !ok {
+			continue
+		}
+
+		rels, err := g.DB.IncomingRelations(asset, time.Time{}, "a_record", "aaaa_record")
+		if err != nil {
+			continue
+		}
+
+		for _, rel := range rels {
+			from, err := g.DB.FindById(rel.FromAsset.ID, time.Time{})
+			if err != nil {
+				continue
+			}
+
+			fqdn, ok := from.Asset.(domain.FQDN)
+			if !ok {
+				continue
+			}
+
+			o, found := lookup[fqdn.Name]
+			if !found {
+				d, err := amassdns.EffectiveTLDPlusOne(fqdn.Name)
+				if err != nil {
+					continue
+				}
+
+				o = &requests.Output{
+					Name:   fqdn.Name,
+					Domain: d,
+				}
+				lookup[fqdn.Name] = o
+			}
+			o.Addresses = append(o.Addresses, requests.AddressInfo{Address: net.IP(ip.Address.AsSlice())})
+		}
+	}
+
+	filter := stringset.New()
+	defer filter.Close()
+
+	if !asninfo || cache == nil {
+		return removeDuplicates(lookup, filter)
+	}
+	return addInfrastructureInfo(lookup, filter, cache)
+}
+
+func cidrsContain(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func removeDuplicates(lookup outLookup, filter *stringset.Set) []*requests.Output {
 	output := make([]*requests.Output, 0, len(lookup))
 
@@ -199,6 +374,7 @@ func addInfrastructureInfo(lookup outLookup, filter *stringset.Set, cache *reque
 				CIDRStr:     i.Prefix,
 				Netblock:    netblock,
 				Description: i.Description,
+				CDN:         a.CDN,
 			})
 		}
 
@@ -249,7 +425,7 @@ func EventNames(ctx context.Context, g *netmap.Graph, domains []string, since ti
 	}
 
 	for _, n := range names {
-		d, err := publicsuffix.EffectiveTLDPlusOne(n)
+		d, err := amassdns.EffectiveTLDPlusOne(n)
 		if err != nil {
 			continue
 		}
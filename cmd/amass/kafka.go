@@ -0,0 +1,199 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/config/config"
+)
+
+// The number of attempts made to produce each batch of results before they are given up.
+const maxKafkaAttempts = 3
+
+// The content type of the records produced through the Kafka REST Proxy v2 API.
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// kafkaSink produces the results of the enumeration to the Kafka topic provided in the output section
+// of the configuration options, one JSON message per result. The messages are produced through the
+// Kafka REST Proxy, so the brokers are the URLs of the proxies in front of the cluster.
+type kafkaSink struct {
+	Brokers  []string
+	Topic    string
+	KeyField string
+}
+
+// kafkaRecord is a message produced to the topic.
+type kafkaRecord struct {
+	Key   *string        `json:"key"`
+	Value *assetRelation `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []*kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// loadKafkaSink returns nil when the Kafka output has not been configured.
+func loadKafkaSink(cfg *config.Config) (*kafkaSink, error) {
+	outputRaw, ok := cfg.Options["output"]
+	if !ok {
+		return nil, nil
+	}
+
+	output, ok := outputRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output is not a map[string]interface{}")
+	}
+
+	kafkaRaw, ok := output["kafka"]
+	if !ok {
+		return nil, nil
+	}
+
+	settings, ok := kafkaRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output kafka is not a map[string]interface{}")
+	}
+
+	brokersRaw, ok := settings["brokers"].([]interface{})
+	if !ok || len(brokersRaw) == 0 {
+		return nil, fmt.Errorf("output kafka brokers is not a list of URLs")
+	}
+
+	sink := new(kafkaSink)
+	for _, raw := range brokersRaw {
+		broker, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("output kafka brokers is not a list of URLs")
+		}
+		if u, err := url.Parse(broker); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("output kafka broker %s is not a valid URL", broker)
+		}
+		sink.Brokers = append(sink.Brokers, strings.TrimRight(broker, "/"))
+	}
+
+	sink.Topic, ok = settings["topic"].(string)
+	if !ok || sink.Topic == "" {
+		return nil, fmt.Errorf("output kafka topic is not a string")
+	}
+
+	if raw, found := settings["key_field"]; found {
+		sink.KeyField, ok = raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("output kafka key_field is not a string")
+		}
+	}
+	return sink, nil
+}
+
+// Send produces a message for each result. The batch is sent to each broker in turn, and the
+// messages rejected by the cluster are sent again, until maxKafkaAttempts have been made.
+func (k *kafkaSink) Send(ctx context.Context, results []*assetRelation) error {
+	var records []*kafkaRecord
+	for _, rel := range results {
+		records = append(records, &kafkaRecord{
+			Key:   k.key(rel),
+			Value: rel,
+		})
+	}
+
+	var err error
+	for attempt := 0; attempt < maxKafkaAttempts && len(records) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+
+		broker := k.Brokers[attempt%len(k.Brokers)]
+		records, err = k.produce(ctx, broker, records)
+	}
+	if len(records) > 0 {
+		return fmt.Errorf("%d messages were not produced to the %s topic: %v", len(records), k.Topic, err)
+	}
+	return nil
+}
+
+// produce returns the records that were not accepted by the cluster.
+func (k *kafkaSink) produce(ctx context.Context, broker string, records []*kafkaRecord) ([]*kafkaRecord, error) {
+	body, err := json.Marshal(&kafkaProduceRequest{Records: records})
+	if err != nil {
+		return records, err
+	}
+
+	resp, err := amasshttp.RequestWebPage(ctx, &amasshttp.Request{
+		URL:    broker + "/topics/" + url.PathEscape(k.Topic),
+		Method: "POST",
+		Header: amasshttp.Header{
+			"Content-Type": kafkaContentType,
+			"Accept":       "application/vnd.kafka.v2+json",
+		},
+		Body: string(body),
+	})
+	if err != nil {
+		return records, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return records, fmt.Errorf("the broker %s returned status %s", broker, resp.Status)
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal([]byte(resp.Body), &produced); err != nil {
+		return records, fmt.Errorf("failed to parse the response of the broker %s: %v", broker, err)
+	}
+
+	var failed []*kafkaRecord
+	for i, off := range produced.Offsets {
+		if off.ErrorCode != nil && i < len(records) {
+			failed = append(failed, records[i])
+			err = fmt.Errorf("the broker %s rejected a message: %s", broker, off.Error)
+		}
+	}
+	if len(failed) == 0 {
+		err = nil
+	}
+	return failed, err
+}
+
+// key returns the value of the configured field of the result, which the cluster uses to select the
+// partition. Without a key field, or when the result does not have the field, the key is null.
+func (k *kafkaSink) key(rel *assetRelation) *string {
+	if k.KeyField == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(rel)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	v, found := fields[k.KeyField]
+	if !found || v == nil {
+		return nil
+	}
+
+	key := fmt.Sprint(v)
+	return &key
+}
@@ -0,0 +1,107 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/enum"
+)
+
+// The tag and source reported by the legacy JSON output for names that no data source provided,
+// such as those discovered while resolving other names.
+const (
+	legacyDNSTag    = "dns"
+	legacyDNSSource = "DNS"
+)
+
+// jsonSave is a single line of the legacy v4 JSON output, which is only provided for compatibility
+// with tools that have not moved to the current output formats.
+type jsonSave struct {
+	Name      string     `json:"name"`
+	Domain    string     `json:"domain"`
+	Addresses []jsonAddr `json:"addresses"`
+	Tag       string     `json:"tag"`
+	Source    string     `json:"source"`
+}
+
+type jsonAddr struct {
+	IP   string `json:"ip"`
+	CIDR string `json:"cidr"`
+	ASN  int    `json:"asn"`
+	Desc string `json:"desc"`
+}
+
+// writeLegacyJSON writes a line in the legacy v4 JSON shape for each name discovered by the enumeration.
+// The output is printed to stdout when the path is "-", and the names end with a dot when trailingDot is true.
+func writeLegacyJSON(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, path string, compress, trailingDot bool) error {
+	var out io.Writer = color.Output
+	if path != "-" {
+		f, err := createOutputFile(path, compress)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	tags := dataSourceTags(e)
+	enc := json.NewEncoder(out)
+	for _, o := range ExtractOutput(ctx, g, e, nil, true) {
+		tag, source := nameTagAndSource(e, tags, o.Name)
+		save := &jsonSave{
+			Name:      o.Name,
+			Domain:    o.Domain,
+			Addresses: []jsonAddr{},
+			Tag:       tag,
+			Source:    source,
+		}
+		if trailingDot {
+			save.Name = fqdnWithTrailingDot(save.Name)
+			save.Domain = fqdnWithTrailingDot(save.Domain)
+		}
+
+		for _, a := range o.Addresses {
+			save.Addresses = append(save.Addresses, jsonAddr{
+				IP:   a.Address.String(),
+				CIDR: a.CIDRStr,
+				ASN:  a.ASN,
+				Desc: a.Description,
+			})
+		}
+		if err := enc.Encode(save); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataSourceTags returns the type of each data source, such as api, cert and scrape, keyed by the lowercase name.
+func dataSourceTags(e *enum.Enumeration) map[string]string {
+	tags := make(map[string]string)
+
+	for _, src := range e.Sys.DataSources() {
+		tags[strings.ToLower(src.String())] = src.Description()
+	}
+	return tags
+}
+
+// nameTagAndSource returns the tag and the first data source, alphabetically, that reported the name.
+func nameTagAndSource(e *enum.Enumeration, tags map[string]string, name string) (string, string) {
+	tag, source := legacyDNSTag, legacyDNSSource
+
+	if srcs := e.Sources(name); len(srcs) > 0 {
+		source = srcs[0]
+		if t, found := tags[strings.ToLower(srcs[0])]; found && t != "" {
+			tag = t
+		}
+	}
+	return tag, source
+}
@@ -24,6 +24,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
@@ -40,10 +41,12 @@ import (
 )
 
 const (
-	mainUsageMsg         = "intel|enum [options]"
+	mainUsageMsg         = "intel|enum|subs|db|viz|config|track|asn [options]"
 	exampleConfigFileURL = "https://github.com/owasp-amass/amass/blob/master/examples/config.yaml"
 	userGuideURL         = "https://github.com/owasp-amass/amass/blob/master/doc/user_guide.md"
 	tutorialURL          = "https://github.com/owasp-amass/amass/blob/master/doc/tutorial.md"
+	// The exit code used when the output directory cannot be written to (EX_NOPERM)
+	exitOutputDirNotWritable = 77
 )
 
 var (
@@ -69,6 +72,12 @@ func commandUsage(msg string, cmdFlagSet *flag.FlagSet, errBuf *bytes.Buffer) {
 		g.Fprintf(color.Error, "\nSubcommands: \n\n")
 		g.Fprintf(color.Error, "\t%-11s - Discover targets for enumerations\n", "amass intel")
 		g.Fprintf(color.Error, "\t%-11s - Perform enumerations and network mapping\n", "amass enum")
+		g.Fprintf(color.Error, "\t%-11s - Read the subdomains discovered by past enumerations\n", "amass subs")
+		g.Fprintf(color.Error, "\t%-11s - Maintain the information stored in the graph database\n", "amass db")
+		g.Fprintf(color.Error, "\t%-11s - Generate visualizations from saved NDJSON results\n", "amass viz")
+		g.Fprintf(color.Error, "\t%-11s - Write a starter configuration and data sources file\n", "amass config")
+		g.Fprintf(color.Error, "\t%-11s - Print the assets as they are inserted into the graph database\n", "amass track")
+		g.Fprintf(color.Error, "\t%-11s - Search the organizations stored in the graph database for their ASNs\n", "amass asn")
 	}
 
 	g.Fprintln(color.Error)
@@ -110,6 +119,18 @@ func main() {
 		runEnumCommand(os.Args[2:])
 	case "intel":
 		runIntelCommand(os.Args[2:])
+	case "subs":
+		runSubsCommand(os.Args[2:])
+	case "db":
+		runDBCommand(os.Args[2:])
+	case "viz":
+		runVizCommand(os.Args[2:])
+	case "config":
+		runConfigCommand(os.Args[2:])
+	case "track":
+		runTrackCommand(os.Args[2:])
+	case "asn":
+		runASNCommand(os.Args[2:])
 	case "help":
 		runHelpCommand(os.Args[2:])
 	default:
@@ -173,9 +194,12 @@ func createOutputDirectory(cfg *config.Config) {
 		r.Fprintln(color.Error, "Failed to obtain the output directory")
 		os.Exit(1)
 	}
-	// If the directory does not yet exist, create it
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		r.Fprintf(color.Error, "Failed to create the directory: %v\n", err)
+	// Detect permission problems before any of the engine is started
+	if err := systems.CheckOutputDirectory(dir); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		if errors.Is(err, systems.ErrOutputDirNotWritable) {
+			os.Exit(exitOutputDirNotWritable)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,131 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// movedName is a name with addresses seen since the provided time that differ from its earlier addresses.
+type movedName struct {
+	Name string   `json:"name"`
+	Old  []string `json:"old"`
+	New  []string `json:"new"`
+}
+
+// trackAddrChanges prints the in-scope names with an address set seen since the provided time that
+// differs from the addresses the name had before the time. Names without addresses seen since the
+// time have not been refreshed, and names without addresses before the time are new, so neither is reported.
+func trackAddrChanges(ctx context.Context, g *netmap.Graph, domains []string, since time.Time, asJSON bool) {
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, domain.FQDN{Name: d})
+	}
+
+	assets, err := g.DB.FindByScope(fqdns, time.Time{})
+	if err != nil {
+		return
+	}
+
+	since = since.UTC()
+	var moved []*movedName
+	for _, a := range assets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		n, ok := a.Asset.(domain.FQDN)
+		if !ok {
+			continue
+		}
+
+		before, after := addrSetsAround(g, a, since)
+		if len(before) == 0 || len(after) == 0 {
+			continue
+		}
+
+		old := uniqueSorted(before)
+		cur := uniqueSorted(after)
+		if len(old) != len(cur) || !containsAll(cur, old) {
+			moved = append(moved, &movedName{Name: n.Name, Old: old, New: cur})
+		}
+	}
+
+	sort.Slice(moved, func(i, j int) bool { return moved[i].Name < moved[j].Name })
+	for _, m := range moved {
+		printMovedName(m, asJSON)
+	}
+}
+
+// addrSetsAround returns the addresses of the name that were first seen before the provided time,
+// and the addresses that were last seen at or after the time. The address records are found
+// directly on the name or by following its CNAME records.
+func addrSetsAround(g *netmap.Graph, name *types.Asset, since time.Time) ([]string, []string) {
+	var before, after []string
+
+	cur := name
+	visited := make(map[string]struct{})
+	for i := 0; i < 10 && cur != nil; i++ {
+		if _, found := visited[cur.ID]; found {
+			break
+		}
+		visited[cur.ID] = struct{}{}
+
+		if rels, err := g.DB.OutgoingRelations(cur, time.Time{}, "a_record", "aaaa_record"); err == nil {
+			for _, rel := range rels {
+				to, err := g.DB.FindById(rel.ToAsset.ID, time.Time{})
+				if err != nil {
+					continue
+				}
+
+				ip, ok := to.Asset.(network.IPAddress)
+				if !ok {
+					continue
+				}
+
+				addr := ip.Address.String()
+				if rel.CreatedAt.Before(since) {
+					before = append(before, addr)
+				}
+				if !rel.LastSeen.Before(since) {
+					after = append(after, addr)
+				}
+			}
+		}
+
+		prev := cur
+		cur = nil
+		if rels, err := g.DB.OutgoingRelations(prev, time.Time{}, "cname_record"); err == nil && len(rels) > 0 {
+			if to, err := g.DB.FindById(rels[0].ToAsset.ID, time.Time{}); err == nil {
+				cur = to
+			}
+		}
+	}
+	return before, after
+}
+
+func printMovedName(m *movedName, asJSON bool) {
+	if asJSON {
+		if data, err := json.Marshal(m); err == nil {
+			fmt.Fprintln(color.Output, string(data))
+		}
+		return
+	}
+
+	fmt.Fprintf(color.Output, "%s %s -> %s\n", green(m.Name),
+		white(strings.Join(m.Old, ",")), white(strings.Join(m.New, ",")))
+}
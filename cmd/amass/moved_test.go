@@ -0,0 +1,47 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/owasp-amass/open-asset-model/domain"
+)
+
+func TestAddrSetsAroundCNAME(t *testing.T) {
+	ctx := context.Background()
+	g := netmap.NewGraph("memory", "", "")
+
+	if err := g.UpsertCNAME(ctx, "www.example.com", "cdn.example.net"); err != nil {
+		t.Fatalf("Failed to insert the CNAME record: %v", err)
+	}
+	if err := g.UpsertA(ctx, "cdn.example.net", "192.0.2.1"); err != nil {
+		t.Fatalf("Failed to insert the first A record: %v", err)
+	}
+	// The stored times may only have a precision of seconds
+	time.Sleep(1100 * time.Millisecond)
+	since := time.Now().UTC()
+	time.Sleep(1100 * time.Millisecond)
+	if err := g.UpsertA(ctx, "cdn.example.net", "192.0.2.2"); err != nil {
+		t.Fatalf("Failed to insert the second A record: %v", err)
+	}
+
+	assets, err := g.DB.FindByContent(domain.FQDN{Name: "www.example.com"}, time.Time{})
+	if err != nil || len(assets) == 0 {
+		t.Fatalf("Failed to find the name behind the CNAME record: %v", err)
+	}
+
+	before, after := addrSetsAround(g, assets[0], since)
+	if want := []string{"192.0.2.1"}; !reflect.DeepEqual(before, want) {
+		t.Errorf("Expected the addresses %v before the time, got %v", want, before)
+	}
+	if want := []string{"192.0.2.2"}; !reflect.DeepEqual(after, want) {
+		t.Errorf("Expected the addresses %v after the time, got %v", want, after)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/enum"
+)
+
+// writePerfReport prints the performance report to stderr and/or saves it to the JSON file.
+func writePerfReport(report *enum.PerfReport, show bool, path string) {
+	if show {
+		fmt.Fprintf(color.Error, "\n%s %s\n", blue("Performance report - elapsed:"), yellow(seconds(report.Elapsed)))
+
+		fmt.Fprintf(color.Error, "%s\n", blue("Stage            Count        Total      Average"))
+		for _, s := range report.Stages {
+			fmt.Fprintf(color.Error, "%s  %s  %s  %s\n", green(fmt.Sprintf("%-15s", s.Name)),
+				yellow(fmt.Sprintf("%5d", s.Count)), yellow(fmt.Sprintf("%11s", seconds(s.Total))),
+				yellow(fmt.Sprintf("%11s", seconds(s.Average))))
+		}
+
+		fmt.Fprintf(color.Error, "%s\n", blue("Data Source              Requests  Results      Elapsed"))
+		for _, s := range report.Sources {
+			fmt.Fprintf(color.Error, "%s  %s  %s  %s\n", green(fmt.Sprintf("%-23s", s.Name)),
+				yellow(fmt.Sprintf("%8d", s.Requests)), yellow(fmt.Sprintf("%7d", s.Results)),
+				yellow(fmt.Sprintf("%11s", seconds(s.Elapsed))))
+		}
+	}
+
+	if path != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to write the performance report: %v\n", err)
+		}
+	}
+}
+
+func seconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64) + "s"
+}
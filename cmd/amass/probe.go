@@ -0,0 +1,84 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/owasp-amass/amass/v4/enum"
+)
+
+// loadHTTPProbes reads the HTTP probe results from the file, keeping the latest result for each URL.
+// Only names within the provided domains are returned, or all the names when no domains are provided.
+func loadHTTPProbes(path string, domains []string) ([]*enum.HTTPProbeResult, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no HTTP probe results have been saved; enable the http_probe section of the configuration")
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byURL := make(map[string]*enum.HTTPProbeResult)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var res enum.HTTPProbeResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", enum.HTTPProbeFilename, line, err)
+		}
+		if !nameInDomains(res.Name, domains) {
+			continue
+		}
+		if prev, found := byURL[res.URL]; !found || res.Seen.After(prev.Seen) {
+			byURL[res.URL] = &res
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	output := make([]*enum.HTTPProbeResult, 0, len(byURL))
+	for _, res := range byURL {
+		output = append(output, res)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Name != output[j].Name {
+			return output[i].Name < output[j].Name
+		}
+		return output[i].URL < output[j].URL
+	})
+	return output, nil
+}
+
+// writeHTTPProbes writes a line for each URL, containing the status code and server header of the response.
+func writeHTTPProbes(out io.Writer, results []*enum.HTTPProbeResult) {
+	for _, res := range results {
+		status := strconv.Itoa(res.Status)
+		if res.Status >= 400 {
+			status = yellow(status)
+		} else {
+			status = green(status)
+		}
+
+		line := fmt.Sprintf("%s %s", res.URL, status)
+		if res.Server != "" {
+			line += blue(" [" + res.Server + "]")
+		}
+		fmt.Fprintln(out, line)
+	}
+}
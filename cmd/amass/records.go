@@ -0,0 +1,137 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/amass/v4/enum"
+)
+
+// nameRecords contains the raw DNS answers recorded for a single name.
+type nameRecords struct {
+	Name    string            `json:"name"`
+	Records []*enum.DNSRecord `json:"records"`
+}
+
+// loadDNSRecords reads the raw DNS answers from the file and groups them by name.
+// Only names within the provided domains are returned, or all the names when no domains are provided.
+func loadDNSRecords(path string, domains []string) ([]*nameRecords, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no DNS records have been saved; enable record_answers in the dns section of the configuration")
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byName := make(map[string]*nameRecords)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var rec enum.DNSRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", enum.DNSRecordsFilename, line, err)
+		}
+		if !nameInDomains(rec.Name, domains) {
+			continue
+		}
+
+		nr, found := byName[rec.Name]
+		if !found {
+			nr = &nameRecords{Name: rec.Name}
+			byName[rec.Name] = nr
+		}
+		nr.Records = append(nr.Records, &rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	output := make([]*nameRecords, 0, len(byName))
+	for _, nr := range byName {
+		output = append(output, nr)
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].Name < output[j].Name })
+	return output, nil
+}
+
+// parseRecordTypes returns the DNS record types in upper case, or an error for an unknown record type.
+func parseRecordTypes(list []string) ([]string, error) {
+	var rtypes []string
+
+	for _, t := range list {
+		rtype := strings.ToUpper(strings.TrimSpace(t))
+		if _, found := dns.StringToType[rtype]; !found {
+			return nil, fmt.Errorf("%s is not a DNS record type", t)
+		}
+		rtypes = append(rtypes, rtype)
+	}
+	return rtypes, nil
+}
+
+// namesWithRecordTypes returns the names having a recorded DNS answer of any of the record types.
+func namesWithRecordTypes(records []*nameRecords, rtypes []string) *stringset.Set {
+	names := stringset.New()
+
+	for _, nr := range records {
+		for _, rec := range nr.Records {
+			if containsType(rtypes, rec.Type) {
+				names.Insert(nr.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+func containsType(rtypes []string, rtype string) bool {
+	for _, t := range rtypes {
+		if t == rtype {
+			return true
+		}
+	}
+	return false
+}
+
+func nameInDomains(name string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+
+	for _, d := range domains {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeDNSRecords writes a JSON line for each name, containing the raw DNS answers recorded for it.
+func writeDNSRecords(out io.Writer, records []*nameRecords) error {
+	enc := json.NewEncoder(out)
+
+	for _, nr := range records {
+		if err := enc.Encode(nr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/owasp-amass/amass/v4/enum"
+	"github.com/owasp-amass/config/config"
+)
+
+// seedRetryNames provides the names that could not be resolved during the prior run to the enumeration,
+// along with the root domain names of those names when none were provided. The names outside of the
+// current scope or within the blacklist are not seeded. Brute forcing and name alterations are turned
+// off, since the retry only completes the portions of the run that failed. The number of names seeded
+// is returned.
+func seedRetryNames(cfg *config.Config, runID string) (int, error) {
+	failed, err := enum.LoadFailedNames(cfg, runID)
+	if err != nil || len(failed) == 0 {
+		return 0, err
+	}
+
+	patterns, err := enum.BlacklistPatterns(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	var num int
+	scoped := len(cfg.Domains()) > 0
+	for _, fn := range failed {
+		if scoped && cfg.WhichDomain(fn.Name) == "" {
+			continue
+		}
+		if retryBlacklisted(cfg, patterns, fn.Name) {
+			continue
+		}
+		if !scoped {
+			cfg.AddDomains(fn.Domain)
+		}
+		cfg.ProvidedNames = append(cfg.ProvidedNames, fn.Name)
+		num++
+	}
+
+	cfg.BruteForcing = false
+	cfg.Alterations = false
+	return num, nil
+}
+
+// retryBlacklisted returns true when the name matches a subdomain suffix or a regular expression of the blacklist.
+func retryBlacklisted(cfg *config.Config, patterns []*regexp.Regexp, name string) bool {
+	if cfg.Blacklisted(name) {
+		return true
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	for _, re := range patterns {
+		if re.MatchString(n) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/config/config"
+)
+
+// runsFilename is the file in the output directory where the enumeration runs are recorded.
+const runsFilename = "amass_runs.ndjson"
+
+// runAssetsFilename is the file in the output directory where the assets extracted by each run are recorded.
+const runAssetsFilename = "amass_run_assets.ndjson"
+
+// runRecord describes a single enumeration run and the tags provided for it.
+type runRecord struct {
+	ID      string            `json:"id,omitempty"`
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Domains []string          `json:"domains"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	// The ID of the prior run whose failed names were retried
+	Retry string `json:"retry,omitempty"`
+	// The subdirectory holding the log and output files of the run
+	Dir string `json:"dir,omitempty"`
+}
+
+// Matches returns true when the run has all the provided tags.
+func (r *runRecord) Matches(tags map[string]string) bool {
+	for k, v := range tags {
+		if val, found := r.Tags[k]; !found || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+func runsFilepath(cfg *config.Config) string {
+	return filepath.Join(config.OutputDirectory(cfg.Dir), runsFilename)
+}
+
+// recordRun appends the run to the runs file in the output directory.
+func recordRun(cfg *config.Config, run *runRecord) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(runsFilepath(cfg), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// loadRuns returns the runs recorded in the output directory that have all the provided tags.
+func loadRuns(cfg *config.Config, tags map[string]string) ([]*runRecord, error) {
+	f, err := os.Open(runsFilepath(cfg))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []*runRecord
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var run runRecord
+		if err := json.Unmarshal(data, &run); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", runsFilename, line, err)
+		}
+		if run.Matches(tags) {
+			runs = append(runs, &run)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// runAssetsLog appends the assets extracted by an enumeration run to the run assets file, each
+// line holding the run ID, so the assets can later be selected by the tags of their runs.
+type runAssetsLog struct {
+	RunID string
+	file  *os.File
+	// The assets already recorded for the run
+	seen *stringset.Set
+}
+
+// runAsset is a line of the run assets file.
+type runAsset struct {
+	RunID string `json:"run_id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+}
+
+func runAssetKey(name, atype string) string {
+	return atype + ":" + name
+}
+
+// newRunAssetsLog opens the run assets file in the output directory for the run.
+func newRunAssetsLog(cfg *config.Config, runID string) (*runAssetsLog, error) {
+	f, err := os.OpenFile(filepath.Join(config.OutputDirectory(cfg.Dir), runAssetsFilename), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the run assets file: %v", err)
+	}
+
+	return &runAssetsLog{
+		RunID: runID,
+		file:  f,
+		seen:  stringset.New(),
+	}, nil
+}
+
+// Add records both assets of the relation, unless they were already recorded for the run.
+func (l *runAssetsLog) Add(rel *assetRelation) {
+	for _, a := range [][2]string{{rel.From, rel.FromType}, {rel.To, rel.ToType}} {
+		key := runAssetKey(a[0], a[1])
+		if a[0] == "" || l.seen.Has(key) {
+			continue
+		}
+		l.seen.Insert(key)
+
+		if data, err := json.Marshal(&runAsset{RunID: l.RunID, Name: a[0], Type: a[1]}); err == nil {
+			_, _ = l.file.Write(append(data, '\n'))
+		}
+	}
+}
+
+// Close closes the run assets file.
+func (l *runAssetsLog) Close() error {
+	l.seen.Close()
+	return l.file.Close()
+}
+
+// loadRunAssets returns the keys of the assets recorded for the runs, as returned by runAssetKey.
+func loadRunAssets(cfg *config.Config, runs []*runRecord) (*stringset.Set, error) {
+	ids := stringset.New()
+	defer ids.Close()
+
+	for _, run := range runs {
+		if run.ID != "" {
+			ids.Insert(run.ID)
+		}
+	}
+
+	assets := stringset.New()
+	f, err := os.Open(filepath.Join(config.OutputDirectory(cfg.Dir), runAssetsFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return assets, nil
+	} else if err != nil {
+		assets.Close()
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var a runAsset
+		if err := json.Unmarshal(data, &a); err != nil {
+			assets.Close()
+			return nil, fmt.Errorf("%s line %d: %v", runAssetsFilename, line, err)
+		}
+		if ids.Has(a.RunID) {
+			assets.Insert(runAssetKey(a.Name, a.Type))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		assets.Close()
+		return nil, err
+	}
+	return assets, nil
+}
+
+// loadTaggedRunAssets returns the keys of the assets extracted by the runs having all the provided tags.
+// It returns nil, after printing the reason, when no runs have the tags.
+func loadTaggedRunAssets(cfg *config.Config, tags format.ParseTags) *stringset.Set {
+	runs, err := loadRuns(cfg, tags)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to load the enumeration runs: %v\n", err)
+		os.Exit(1)
+	}
+	if len(runs) == 0 {
+		r.Fprintf(color.Error, "No enumeration runs have the tags %s\n", tags.String())
+		return nil
+	}
+
+	assets, err := loadRunAssets(cfg, runs)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to load the assets of the enumeration runs: %v\n", err)
+		os.Exit(1)
+	}
+	return assets
+}
+
+// namesFromRuns returns the names that were extracted by one of the runs.
+func namesFromRuns(assets *stringset.Set, names []*requests.Output) []*requests.Output {
+	var output []*requests.Output
+
+	for _, o := range names {
+		if assets.Has(runAssetKey(o.Name, "FQDN")) {
+			output = append(output, o)
+		}
+	}
+	return output
+}
+
+// createRunDirectory creates the subdirectory of the output directory named with the RFC3339 start time
+// of the run. The colons are replaced, since they are not allowed in the file names of every platform.
+// When a run started in the same second already has the directory, a numbered suffix is added.
+func createRunDirectory(dir string, start time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create the run directory: %v", err)
+	}
+
+	name := strings.ReplaceAll(start.UTC().Format(time.RFC3339), ":", "-")
+	rundir := filepath.Join(dir, name)
+	for i := 2; ; i++ {
+		// The directory is created atomically, so concurrent runs never share it
+		err := os.Mkdir(rundir, 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create the run directory: %v", err)
+		}
+		rundir = filepath.Join(dir, name+"-"+strconv.Itoa(i))
+	}
+	return rundir, nil
+}
@@ -0,0 +1,171 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/owasp-amass/config/config"
+)
+
+// The minimum size of each part of a multipart upload, except the last part, required by S3.
+const s3PartSize = 5 * 1024 * 1024
+
+// s3Sink streams the results of the enumeration as NDJSON to an object in the S3 bucket provided
+// in the output section of the configuration options, using a multipart upload.
+type s3Sink struct {
+	client   *s3.Client
+	Bucket   string
+	Key      string
+	uploadID string
+	buf      bytes.Buffer
+	parts    []types.CompletedPart
+	err      error
+}
+
+// loadS3Sink returns nil when the S3 output has not been configured. The credentials are obtained from
+// the environment, the shared AWS configuration files or the IAM role, and the object is named with the
+// prefix followed by the run ID.
+func loadS3Sink(ctx context.Context, cfg *config.Config, runID string) (*s3Sink, error) {
+	outputRaw, ok := cfg.Options["output"]
+	if !ok {
+		return nil, nil
+	}
+
+	output, ok := outputRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output is not a map[string]interface{}")
+	}
+
+	s3Raw, ok := output["s3"]
+	if !ok {
+		return nil, nil
+	}
+
+	settings, ok := s3Raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output s3 is not a map[string]interface{}")
+	}
+
+	bucket, ok := settings["bucket"].(string)
+	if !ok || bucket == "" {
+		return nil, fmt.Errorf("output s3 bucket is not a string")
+	}
+
+	var prefix string
+	if raw, found := settings["prefix"]; found {
+		prefix, ok = raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("output s3 prefix is not a string")
+		}
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if raw, found := settings["region"]; found {
+		region, ok := raw.(string)
+		if !ok || region == "" {
+			return nil, fmt.Errorf("output s3 region is not a string")
+		}
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS configuration: %v", err)
+	}
+
+	sink := &s3Sink{
+		client: s3.NewFromConfig(awsCfg),
+		Bucket: bucket,
+		Key:    prefix + "amass_" + runID + ".ndjson",
+	}
+
+	out, err := sink.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(sink.Bucket),
+		Key:         aws.String(sink.Key),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the upload to s3://%s/%s: %v", sink.Bucket, sink.Key, err)
+	}
+	sink.uploadID = aws.ToString(out.UploadId)
+	return sink, nil
+}
+
+// Write adds the result to the upload, sending a part to S3 once enough results have been buffered.
+// After a part fails to upload, the remaining results are ignored and the upload is aborted by Close.
+func (s *s3Sink) Write(ctx context.Context, rel *assetRelation) error {
+	if s.err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(rel)
+	if err != nil {
+		return err
+	}
+	s.buf.Write(append(data, '\n'))
+
+	if s.buf.Len() >= s3PartSize {
+		s.err = s.uploadPart(ctx)
+		return s.err
+	}
+	return nil
+}
+
+func (s *s3Sink) uploadPart(ctx context.Context) error {
+	num := int32(len(s.parts) + 1)
+
+	out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.Bucket),
+		Key:        aws.String(s.Key),
+		UploadId:   aws.String(s.uploadID),
+		PartNumber: num,
+		Body:       bytes.NewReader(s.buf.Bytes()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d to s3://%s/%s: %v", num, s.Bucket, s.Key, err)
+	}
+
+	s.parts = append(s.parts, types.CompletedPart{
+		ETag:       out.ETag,
+		PartNumber: num,
+	})
+	s.buf.Reset()
+	return nil
+}
+
+// Close uploads the remaining results and completes the object, or aborts the upload after a failure.
+func (s *s3Sink) Close(ctx context.Context) error {
+	if s.err == nil && (s.buf.Len() > 0 || len(s.parts) == 0) {
+		s.err = s.uploadPart(ctx)
+	}
+
+	if s.err != nil {
+		_, _ = s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.Bucket),
+			Key:      aws.String(s.Key),
+			UploadId: aws.String(s.uploadID),
+		})
+		return s.err
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(s.Key),
+		UploadId:        aws.String(s.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: s.parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete the upload to s3://%s/%s: %v", s.Bucket, s.Key, err)
+	}
+	return nil
+}
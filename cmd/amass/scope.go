@@ -0,0 +1,114 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// scopeEntry is a single element of the JSON array accepted by the scope-json flag.
+type scopeEntry struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonScope is the scope described within a JSON scope file.
+type jsonScope struct {
+	Domains   []string
+	Addresses []net.IP
+	CIDRs     []*net.IPNet
+	ASNs      []int
+}
+
+// parseScopeJSON reads a JSON array of scope entries from the file at path.
+// All entries are validated and every bad entry is reported in the returned error.
+func parseScopeJSON(path string) (*jsonScope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the JSON scope file: %v", err)
+	}
+
+	var entries []scopeEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse the JSON scope file: %v", err)
+	}
+
+	var bad []string
+	scope := new(jsonScope)
+	for i, entry := range entries {
+		if err := scope.add(entry); err != nil {
+			bad = append(bad, fmt.Sprintf("entry %d: %v", i, err))
+		}
+	}
+
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("the JSON scope file contains bad entries:\n\t%s", strings.Join(bad, "\n\t"))
+	}
+	return scope, nil
+}
+
+func (s *jsonScope) add(entry scopeEntry) error {
+	etype := strings.ToLower(strings.TrimSpace(entry.Type))
+
+	if etype == "asn" {
+		asn, err := scopeASN(entry.Value)
+		if err != nil {
+			return err
+		}
+		s.ASNs = append(s.ASNs, asn)
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(entry.Value, &value); err != nil || value == "" {
+		return fmt.Errorf("the %q value must be a non-empty string", entry.Type)
+	}
+	value = strings.TrimSpace(value)
+
+	switch etype {
+	case "domain", "fqdn":
+		if net.ParseIP(value) != nil || !strings.Contains(value, ".") {
+			return fmt.Errorf("%s is not a valid domain name", value)
+		}
+		s.Domains = append(s.Domains, strings.ToLower(value))
+	case "ip", "address":
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return fmt.Errorf("%s is not a valid IP address", value)
+		}
+		s.Addresses = append(s.Addresses, ip)
+	case "cidr", "netblock":
+		_, ipnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid CIDR", value)
+		}
+		s.CIDRs = append(s.CIDRs, ipnet)
+	default:
+		return fmt.Errorf("%q is not a supported scope type", entry.Type)
+	}
+	return nil
+}
+
+// scopeASN accepts ASNs provided as numbers or strings such as "AS13374".
+func scopeASN(raw json.RawMessage) (int, error) {
+	var num int
+	if err := json.Unmarshal(raw, &num); err == nil && num > 0 {
+		return num, nil
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		str = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(str)), "AS")
+		if num, err := strconv.Atoi(str); err == nil && num > 0 {
+			return num, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a valid ASN", string(raw))
+}
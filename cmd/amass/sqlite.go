@@ -0,0 +1,209 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/owasp-amass/amass/v4/enum"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+
+	// The pure Go SQLite driver registered as "sqlite"
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// The flat schema used for the standalone SQLite results file.
+var sqliteResultsSchema = []string{
+	`CREATE TABLE runs (
+		id INTEGER PRIMARY KEY,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NOT NULL,
+		domains TEXT NOT NULL
+	)`,
+	`CREATE TABLE names (
+		name TEXT PRIMARY KEY,
+		domain TEXT NOT NULL,
+		first_seen TIMESTAMP,
+		last_seen TIMESTAMP
+	)`,
+	`CREATE TABLE addresses (
+		name TEXT NOT NULL,
+		address TEXT NOT NULL,
+		asn INTEGER,
+		cidr TEXT,
+		first_seen TIMESTAMP,
+		last_seen TIMESTAMP,
+		PRIMARY KEY (name, address)
+	)`,
+	`CREATE TABLE sources (
+		name TEXT NOT NULL,
+		source TEXT NOT NULL,
+		PRIMARY KEY (name, source)
+	)`,
+	`CREATE TABLE asns (
+		asn INTEGER PRIMARY KEY,
+		description TEXT
+	)`,
+}
+
+// writeSQLiteResults saves the results of the enumeration into a standalone SQLite file,
+// independent of the graph database used by the enumeration.
+func writeSQLiteResults(ctx context.Context, path string, g *netmap.Graph, e *enum.Enumeration) error {
+	// The file only represents this execution, so previous content is removed
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the existing SQLite results file: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open the SQLite results file: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range sqliteResultsSchema {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create the SQLite results schema: %v", err)
+		}
+	}
+
+	domains := e.Config.Domains()
+	since := e.Config.CollectionStartTime
+	if _, err := tx.ExecContext(ctx, "INSERT INTO runs (started_at, finished_at, domains) VALUES (?, ?, ?)",
+		since.UTC(), time.Now().UTC(), strings.Join(domains, ",")); err != nil {
+		return err
+	}
+
+	cache := e.Sys.Cache()
+	for _, o := range EventOutput(ctx, g, domains, since, nil, false, nil) {
+		seen := newSQLiteSeenTimes(g, o.Name)
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO names (name, domain, first_seen, last_seen) VALUES (?, ?, ?, ?)",
+			o.Name, o.Domain, sqliteTime(seen.first), sqliteTime(seen.last)); err != nil {
+			return err
+		}
+		for _, src := range e.Sources(o.Name) {
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO sources (name, source) VALUES (?, ?)", o.Name, src); err != nil {
+				return err
+			}
+		}
+
+		for _, a := range o.Addresses {
+			var asn int
+			var cidr, desc string
+			if i := cache.AddrSearch(a.Address.String()); i != nil {
+				asn, cidr, desc = i.ASN, i.Prefix, i.Description
+			}
+
+			first, last := seen.addr(g, a.Address.String())
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO addresses (name, address, asn, cidr, first_seen, last_seen) VALUES (?, ?, ?, ?, ?, ?)",
+				o.Name, a.Address.String(), asn, cidr, sqliteTime(first), sqliteTime(last)); err != nil {
+				return err
+			}
+			if asn == 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO asns (asn, description) VALUES (?, ?)", asn, desc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sqliteSeenTimes holds the times a name, and its address records, were first and last seen in the graph database.
+type sqliteSeenTimes struct {
+	first time.Time
+	last  time.Time
+	addrs map[string][2]time.Time
+}
+
+func newSQLiteSeenTimes(g *netmap.Graph, name string) *sqliteSeenTimes {
+	seen := &sqliteSeenTimes{addrs: make(map[string][2]time.Time)}
+
+	assets, err := g.DB.FindByContent(domain.FQDN{Name: name}, time.Time{})
+	if err != nil {
+		return seen
+	}
+
+	for _, a := range assets {
+		seen.first, seen.last = widenSeenTimes(seen.first, seen.last, a.CreatedAt, a.LastSeen)
+
+		rels, err := g.DB.OutgoingRelations(a, time.Time{}, "a_record", "aaaa_record")
+		if err != nil {
+			continue
+		}
+		for _, rel := range rels {
+			to, err := g.DB.FindById(rel.ToAsset.ID, time.Time{})
+			if err != nil {
+				continue
+			}
+			if ip, ok := to.Asset.(network.IPAddress); ok {
+				k := ip.Address.String()
+				t := seen.addrs[k]
+				t[0], t[1] = widenSeenTimes(t[0], t[1], rel.CreatedAt, rel.LastSeen)
+				seen.addrs[k] = t
+			}
+		}
+	}
+	return seen
+}
+
+// addr returns the times of the address record. The addresses reached through CNAME records
+// have no record of their own for the name, so the times of the address asset are used.
+func (s *sqliteSeenTimes) addr(g *netmap.Graph, addr string) (time.Time, time.Time) {
+	if t, found := s.addrs[addr]; found {
+		return t[0], t[1]
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+	t := "IPv4"
+	if ip.Is6() {
+		t = "IPv6"
+	}
+
+	var first, last time.Time
+	if assets, err := g.DB.FindByContent(network.IPAddress{Address: ip, Type: t}, time.Time{}); err == nil {
+		for _, a := range assets {
+			first, last = widenSeenTimes(first, last, a.CreatedAt, a.LastSeen)
+		}
+	}
+	return first, last
+}
+
+// widenSeenTimes returns the earliest first seen and the latest last seen times.
+func widenSeenTimes(first, last, created, seen time.Time) (time.Time, time.Time) {
+	if !created.IsZero() && (first.IsZero() || created.Before(first)) {
+		first = created
+	}
+	if seen.After(last) {
+		last = seen
+	}
+	return first, last
+}
+
+// sqliteTime returns the UTC time, or nil for the NULL value when the time is unknown.
+func sqliteTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,600 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/enum"
+	"github.com/owasp-amass/amass/v4/format"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/amass/v4/resources"
+	"github.com/owasp-amass/amass/v4/systems"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/open-asset-model/domain"
+)
+
+const subsUsageMsg = "subs [options] -d domain | -addr ADDR | -cidr CIDR | -edges ENTITY"
+
+// The layouts accepted by the since flag, which are a date or the time format used by the tracker.
+var subsSinceLayouts = []string{"01/02 15:04:05 2006 MST", "2006-01-02"}
+
+type subsArgs struct {
+	AddrFamily  string
+	Addresses   format.ParseIPs
+	CDNRanges   []*resources.CDNRange
+	CIDRs       format.ParseCIDRs
+	Domains     *stringset.Set
+	Edges       edgeQuery
+	HasRecord   format.ParseStrings
+	OnlyInCIDRs []*net.IPNet
+	Registrars  map[string]*enum.RegistrarRecord
+	RunTags     format.ParseTags
+	Since       string
+	WithRecords *stringset.Set
+	Options     struct {
+		Apex            bool
+		ApexOnly        bool
+		ByASN           bool
+		DemoMode        bool
+		HTTPProbes      bool
+		IPs             bool
+		IPv4            bool
+		IPv6            bool
+		JSON            bool
+		ASNTableSummary bool
+		DiscoveredNames bool
+		ExcludeCDN      bool
+		NoColor         bool
+		Offline         bool
+		RecordsJSON     bool
+		ShowAll         bool
+		Silent          bool
+		Tree            bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		Domains    format.ParseStrings
+		TermOut    string
+	}
+}
+
+func runSubsCommand(clArgs []string) {
+	var args subsArgs
+	var help1, help2 bool
+	subsCommand := flag.NewFlagSet("subs", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	subsBuf := new(bytes.Buffer)
+	subsCommand.SetOutput(subsBuf)
+
+	subsCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	subsCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	subsCommand.Var(&args.Addresses, "addr", "Show the names resolving to these IPs and ranges (192.168.1.1-254) separated by commas")
+	subsCommand.Var(&args.CIDRs, "cidr", "Show the names resolving into these CIDRs separated by commas")
+	subsCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	subsCommand.StringVar(&args.Edges.Entity, "edges", "", "Show the graph edges of this FQDN, IP address, CIDR or ASN (e.g. AS13335)")
+	subsCommand.StringVar(&args.Edges.Relation, "rel", "", "Relation type followed by -edges (default: all relation types)")
+	subsCommand.IntVar(&args.Edges.Depth, "depth", 1, "Number of edges followed from the -edges entity")
+	subsCommand.Var(&args.HasRecord, "has-record", "Only show names with recorded DNS answers of these types separated by commas (e.g. MX,TXT)")
+	subsCommand.BoolVar(&args.Edges.Incoming, "incoming", false, "Follow the incoming edges of the -edges entity")
+	subsCommand.BoolVar(&args.Options.JSON, "json", false, "Print the discovered names as JSON lines, or the -tree and -edges as a JSON document")
+	subsCommand.Var(&args.RunTags, "run-tag", "Only show names extracted by enumeration runs having these tags (key=value)")
+	subsCommand.StringVar(&args.Since, "since", "", "Only show names seen since this time ('01/02 15:04:05 2006 MST' or YYYY-MM-DD)")
+	subsCommand.BoolVar(&args.Options.Apex, "apex", false, "Show the registrar and nameservers for each root domain")
+	subsCommand.BoolVar(&args.Options.ApexOnly, "apex-only", false, "Print just the unique registrable domains of the discovered names")
+	subsCommand.BoolVar(&args.Options.ByASN, "by-asn", false, "Print the discovered names grouped by ASN and netblock")
+	subsCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	subsCommand.BoolVar(&args.Options.HTTPProbes, "http", false, "Print the HTTP status and server recorded by the http_probe for each name")
+	subsCommand.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
+	subsCommand.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
+	subsCommand.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
+	subsCommand.BoolVar(&args.Options.ASNTableSummary, "summary", false, "Print just the ASN table summary")
+	subsCommand.BoolVar(&args.Options.DiscoveredNames, "names", false, "Print just the discovered names")
+	subsCommand.BoolVar(&args.Options.ExcludeCDN, "exclude-cdn", false, "Do not show names with all their addresses in CDN or shared hosting ranges")
+	subsCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	subsCommand.BoolVar(&args.Options.Offline, "offline", false, "Only read the graph database and never attempt network access")
+	subsCommand.BoolVar(&args.Options.RecordsJSON, "records-json", false, "Print the raw DNS answers recorded for each name as JSON lines")
+	subsCommand.BoolVar(&args.Options.ShowAll, "show", false, "Print the discovered names and the ASN table summary")
+	subsCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	subsCommand.BoolVar(&args.Options.Tree, "tree", false, "Print the discovered names as a tree grouped by registrable domain and parent zone")
+	subsCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file. Additional details below")
+	subsCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	subsCommand.Var(&args.Filepaths.Domains, "df", "Path to a file providing root domain names")
+	subsCommand.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+
+	if len(clArgs) < 1 {
+		commandUsage(subsUsageMsg, subsCommand, subsBuf)
+		return
+	}
+	if err := subsCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		commandUsage(subsUsageMsg, subsCommand, subsBuf)
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if args.Options.Offline {
+		amassnet.SetOffline(true)
+	}
+
+	var since time.Time
+	if args.Since != "" {
+		var err error
+
+		for _, layout := range subsSinceLayouts {
+			if since, err = time.Parse(layout, args.Since); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not a valid time ('01/02 15:04:05 2006 MST' or YYYY-MM-DD)\n", args.Since)
+			os.Exit(1)
+		}
+	}
+	if len(args.Filepaths.Domains) > 0 {
+		for _, f := range args.Filepaths.Domains {
+			list, err := config.GetListFromFile(f)
+			if err != nil {
+				r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+				return
+			}
+			args.Domains.InsertMany(list...)
+		}
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+
+	if err := systems.SetPublicSuffixList(cfg); err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ext, err := loadScopeExtensions(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	args.OnlyInCIDRs = ext.OnlyInCIDRs
+
+	if args.Options.ExcludeCDN {
+		args.CDNRanges, err = loadCDNRanges(cfg)
+		if err != nil {
+			r.Fprintf(color.Error, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	args.AddrFamily, err = enum.AddressFamily(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := systems.ValidateDatabases(context.Background(), cfg); err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	db := openGraphDatabase(cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	if args.Options.ShowAll {
+		args.Options.DiscoveredNames = true
+		args.Options.ASNTableSummary = true
+	}
+	if args.Options.JSON {
+		args.Options.DiscoveredNames = true
+		args.Edges.JSON = true
+	}
+	if args.Options.Tree {
+		args.Options.DiscoveredNames = true
+	}
+	if len(args.HasRecord) > 0 {
+		rtypes, err := parseRecordTypes(args.HasRecord)
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+
+		records, err := loadDNSRecords(enum.DNSRecordsFilepath(cfg), args.Domains.Slice())
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the DNS records: %v\n", err)
+			os.Exit(1)
+		}
+		args.WithRecords = namesWithRecordTypes(records, rtypes)
+		defer args.WithRecords.Close()
+	}
+	if args.Options.Apex {
+		args.Registrars, err = enum.LoadRegistrars(cfg)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the registrars: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if args.Options.RecordsJSON {
+		records, err := loadDNSRecords(enum.DNSRecordsFilepath(cfg), args.Domains.Slice())
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the DNS records: %v\n", err)
+			os.Exit(1)
+		}
+		if args.WithRecords != nil {
+			var selected []*nameRecords
+			for _, nr := range records {
+				if args.WithRecords.Has(nr.Name) {
+					selected = append(selected, nr)
+				}
+			}
+			records = selected
+		}
+		if err := writeDNSRecords(color.Output, records); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if args.Options.HTTPProbes {
+		results, err := loadHTTPProbes(enum.HTTPProbeFilepath(cfg), args.Domains.Slice())
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the HTTP probe results: %v\n", err)
+			os.Exit(1)
+		}
+		writeHTTPProbes(color.Output, results)
+		return
+	}
+	if args.Edges.Entity != "" && args.Edges.Depth < 1 {
+		r.Fprintln(color.Error, "The depth must be at least one")
+		os.Exit(1)
+	}
+	if !args.Options.DiscoveredNames && !args.Options.ASNTableSummary && !args.Options.ByASN &&
+		!args.Options.Apex && !args.Options.ApexOnly && args.Edges.Entity == "" {
+		commandUsage(subsUsageMsg, subsCommand, subsBuf)
+		return
+	}
+
+	var asninfo bool
+	// The JSON lines provide the ASN, CIDR and description of each address
+	if args.Options.ASNTableSummary || args.Options.ByASN || args.Options.JSON {
+		asninfo = true
+	}
+
+	var runAssets *stringset.Set
+	if len(args.RunTags) > 0 {
+		runAssets = loadTaggedRunAssets(cfg, args.RunTags)
+		if runAssets == nil {
+			return
+		}
+		defer runAssets.Close()
+	}
+
+	showSubsData(&args, asninfo, db, runAssets, since)
+}
+
+func showSubsData(args *subsArgs, asninfo bool, db *netmap.Graph, runAssets *stringset.Set, since time.Time) {
+	var total int
+	var err error
+	var outfile *os.File
+	domains := args.Domains.Slice()
+
+	if args.Filepaths.TermOut != "" {
+		outfile, err = os.OpenFile(args.Filepaths.TermOut, os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to open the text output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			_ = outfile.Sync()
+			_ = outfile.Close()
+		}()
+		_ = outfile.Truncate(0)
+		_, _ = outfile.Seek(0, 0)
+	}
+
+	if args.Edges.Entity != "" {
+		showEdges(db, &args.Edges, outfile)
+		return
+	}
+
+	var cache *requests.ASNCache
+	if asninfo {
+		cache = requests.NewASNCache()
+	}
+
+	ctx := context.Background()
+	var names []*requests.Output
+	if len(args.Addresses) > 0 || len(args.CIDRs) > 0 {
+		names = AddrOutput(ctx, db, args.Addresses, args.CIDRs, asninfo, cache)
+	} else {
+		names = EventOutput(ctx, db, domains, since, nil, asninfo, cache)
+	}
+	if runAssets != nil {
+		names = namesFromRuns(runAssets, names)
+	}
+	if args.WithRecords != nil {
+		var selected []*requests.Output
+		for _, o := range names {
+			if args.WithRecords.Has(o.Name) {
+				selected = append(selected, o)
+			}
+		}
+		names = selected
+	}
+	if args.Options.ApexOnly {
+		showApexDomains(names, args.Options.DemoMode, outfile)
+		return
+	}
+	if args.Options.Apex {
+		showApexInfo(db, args.Registrars, domains, names, args.Options.DemoMode, outfile)
+	}
+	if !args.Options.DiscoveredNames && !args.Options.ASNTableSummary && !args.Options.ByASN {
+		return
+	}
+
+	// Without the ipv4 and ipv6 flags, the addresses shown are those kept by the configured address family
+	ipv4, ipv6 := args.Options.IPv4, args.Options.IPv6
+	if !ipv4 && !ipv6 {
+		ipv4 = args.AddrFamily != enum.AddressFamilyIPv6
+		ipv6 = args.AddrFamily != enum.AddressFamilyIPv4
+	}
+
+	if args.Options.ExcludeCDN {
+		markCDNAddrs(names, args.CDNRanges)
+	}
+
+	var tree *subsTree
+	if args.Options.Tree {
+		tree = newSubsTree()
+	}
+
+	asnmap := make(map[int]*format.ASNSummaryData)
+	asnnames := make(map[int]*format.ASNNamesData)
+	// Names found by address are always shown with the matching addresses
+	addrs := args.Options.IPs || args.Options.IPv4 || args.Options.IPv6 || len(args.Addresses) > 0 || len(args.CIDRs) > 0
+	for _, out := range names {
+		if len(args.OnlyInCIDRs) > 0 {
+			// Names without an address in the allowed ranges are dropped
+			if out.Addresses = format.AddrsInCIDRs(out.Addresses, args.OnlyInCIDRs); len(out.Addresses) == 0 {
+				continue
+			}
+		}
+		if !ipv4 || !ipv6 {
+			out.Addresses = format.DesiredAddrTypes(out.Addresses, ipv4, ipv6)
+		}
+
+		// Names only reachable through shared infrastructure are not unique to the target
+		if args.Options.ExcludeCDN && onlyCDNAddrs(out) {
+			continue
+		}
+
+		if l := len(out.Addresses); addrs && l == 0 {
+			continue
+		} else if l > 0 {
+			format.UpdateSummaryData(out, asnmap)
+			format.UpdateASNNames(out, asnnames)
+		}
+
+		total++
+		if tree != nil {
+			tree.Insert(out)
+			continue
+		}
+		if args.Options.JSON {
+			writeNameJSON(out, args.Options.DemoMode, outfile)
+			continue
+		}
+
+		name, ips := format.OutputLineParts(out, addrs, args.Options.DemoMode)
+		if ips != "" {
+			ips = " " + ips
+		}
+
+		if args.Options.DiscoveredNames {
+			fmt.Fprintf(color.Output, "%s%s\n", green(name), yellow(ips))
+			if outfile != nil {
+				fmt.Fprintf(outfile, "%s%s\n", name, ips)
+			}
+		}
+	}
+
+	if total == 0 {
+		if args.Options.JSON {
+			r.Fprintln(color.Error, "No names were discovered")
+		} else {
+			r.Println("No names were discovered")
+		}
+		return
+	}
+	// The JSON output is not mixed with the text of the ASN tables
+	if args.Options.JSON {
+		if tree != nil {
+			writeSubsTreeJSON(tree, args.Options.DemoMode, outfile)
+		}
+		return
+	}
+	if tree != nil {
+		fprintSubsTree(tree, addrs, args.Options.DemoMode, outfile)
+	}
+	var out io.Writer = color.Output
+	if outfile != nil {
+		out = io.MultiWriter(color.Output, outfile)
+	}
+	if args.Options.ByASN {
+		format.FprintNamesByASN(out, asnnames, args.Options.DemoMode)
+	}
+	if args.Options.ASNTableSummary {
+		format.FprintEnumerationSummary(out, total, asnmap, args.Options.DemoMode)
+	}
+}
+
+// writeNameJSON prints the name and its addresses as a JSON line, censored when demo is true.
+func writeNameJSON(out *requests.Output, demo bool, outfile *os.File) {
+	data, err := json.Marshal(format.NewOutputRecord(out, demo))
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(color.Output, string(data))
+	if outfile != nil {
+		fmt.Fprintln(outfile, string(data))
+	}
+}
+
+// showApexDomains prints the unique registrable domains, according to the public suffix list, of the names.
+func showApexDomains(names []*requests.Output, demo bool, outfile *os.File) {
+	apexes := stringset.New()
+	defer apexes.Close()
+
+	for _, o := range names {
+		if d, err := amassdns.EffectiveTLDPlusOne(o.Name); err == nil {
+			apexes.Insert(d)
+		} else if o.Domain != "" {
+			apexes.Insert(o.Domain)
+		}
+	}
+
+	list := apexes.Slice()
+	sort.Strings(list)
+	for _, apex := range list {
+		name := apex
+		if demo {
+			name, _ = format.OutputLineParts(&requests.Output{Name: apex}, false, true)
+		}
+
+		fmt.Fprintln(color.Output, green(name))
+		if outfile != nil {
+			fmt.Fprintln(outfile, name)
+		}
+	}
+}
+
+// showApexInfo prints the registrar and authoritative nameservers recorded for each root domain name.
+func showApexInfo(db *netmap.Graph, registrars map[string]*enum.RegistrarRecord, domains []string, names []*requests.Output, demo bool, outfile *os.File) {
+	apexes := stringset.New(domains...)
+	defer apexes.Close()
+
+	for _, o := range names {
+		if o.Domain != "" {
+			apexes.Insert(o.Domain)
+		}
+	}
+
+	list := apexes.Slice()
+	sort.Strings(list)
+	for _, apex := range list {
+		registrar := "unknown"
+		if rec, found := registrars[apex]; found {
+			registrar = rec.Registrar
+		}
+
+		ns := strings.Join(apexNameservers(db, apex), ",")
+		if ns == "" {
+			ns = "unknown"
+		}
+
+		name := apex
+		if demo {
+			name, _ = format.OutputLineParts(&requests.Output{Name: apex}, false, true)
+		}
+
+		fmt.Fprintf(color.Output, "%s %s %s %s %s\n", green(name), blue("registrar:"), yellow(registrar), blue("nameservers:"), yellow(ns))
+		if outfile != nil {
+			fmt.Fprintf(outfile, "%s registrar: %s nameservers: %s\n", name, registrar, ns)
+		}
+	}
+}
+
+// apexNameservers returns the names of the nameservers linked to the apex by NS records in the graph.
+func apexNameservers(db *netmap.Graph, apex string) []string {
+	servers := stringset.New()
+	defer servers.Close()
+
+	assets, err := db.DB.FindByContent(domain.FQDN{Name: apex}, time.Time{})
+	if err != nil {
+		return nil
+	}
+
+	for _, a := range assets {
+		rels, err := db.DB.OutgoingRelations(a, time.Time{}, "ns_record")
+		if err != nil {
+			continue
+		}
+
+		for _, rel := range rels {
+			if to, err := db.DB.FindById(rel.ToAsset.ID, time.Time{}); err == nil {
+				if fqdn, ok := to.Asset.(domain.FQDN); ok {
+					servers.Insert(fqdn.Name)
+				}
+			}
+		}
+	}
+
+	list := servers.Slice()
+	sort.Strings(list)
+	return list
+}
+
+// openGraphDatabase connects to the primary graph database identified by the configuration.
+func openGraphDatabase(cfg *config.Config) *netmap.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if !db.Primary {
+			continue
+		}
+
+		g, err := systems.NewGraphDatabase(cfg, db)
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			return nil
+		}
+		return g
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/owasp-amass/amass/v4/enum"
+	"github.com/owasp-amass/amass/v4/format"
+)
+
+// runSummary is the machine-readable summary of an enumeration written by the -summary flag.
+type runSummary struct {
+	RunID    string         `json:"run_id"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Duration float64        `json:"duration"`
+	Names    int            `json:"names"`
+	Sources  map[string]int `json:"sources"`
+	Tags     map[string]int `json:"tags"`
+	ASNs     []*summaryASN  `json:"asns"`
+}
+
+// summaryASN holds the number of addresses discovered within each netblock of an autonomous system.
+type summaryASN struct {
+	ASN         int            `json:"asn"`
+	Description string         `json:"desc"`
+	Netblocks   map[string]int `json:"netblocks"`
+}
+
+// writeRunSummary saves the summary of the names discovered by the enumeration to the JSON file.
+// Each name is counted once for every data source that reported it, and once for its tag.
+func writeRunSummary(ctx context.Context, g *netmap.Graph, e *enum.Enumeration, runID string, start, end time.Time, path string) error {
+	summary := &runSummary{
+		RunID:    runID,
+		Start:    start.UTC(),
+		End:      end.UTC(),
+		Duration: end.Sub(start).Seconds(),
+		Sources:  make(map[string]int),
+		Tags:     make(map[string]int),
+		ASNs:     []*summaryASN{},
+	}
+
+	tags := dataSourceTags(e)
+	asns := make(map[int]*format.ASNSummaryData)
+	for _, o := range ExtractOutput(ctx, g, e, nil, true) {
+		summary.Names++
+		format.UpdateSummaryData(o, asns)
+
+		tag, source := nameTagAndSource(e, tags, o.Name)
+		summary.Tags[tag]++
+
+		srcs := e.Sources(o.Name)
+		if len(srcs) == 0 {
+			srcs = []string{source}
+		}
+		for _, src := range srcs {
+			summary.Sources[src]++
+		}
+	}
+
+	for asn, data := range asns {
+		summary.ASNs = append(summary.ASNs, &summaryASN{
+			ASN:         asn,
+			Description: data.Name,
+			Netblocks:   data.Netblocks,
+		})
+	}
+	sort.Slice(summary.ASNs, func(i, j int) bool { return summary.ASNs[i].ASN < summary.ASNs[j].ASN })
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
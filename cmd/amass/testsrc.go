@@ -0,0 +1,123 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/service"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/datasrcs"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/amass/v4/systems"
+	"github.com/owasp-amass/config/config"
+)
+
+// The time permitted for a data source test when the timeout flag has not been provided.
+const defaultSourceTestTimeout = 2 * time.Minute
+
+// testDataSource starts only the named data source, sends it a single request for the domain and prints
+// the raw results. The messages logged during the test, such as authentication and rate limiting errors,
+// are printed to stderr.
+func testDataSource(cfg *config.Config, name, domain string, timeout time.Duration) error {
+	cfg.Log = log.New(color.Error, yellow("[log] "), log.Ltime)
+
+	sys, err := systems.NewLocalSystem(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sys.Shutdown() }()
+
+	var src service.Service
+	for _, s := range datasrcs.GetAllSources(sys) {
+		if strings.EqualFold(s.String(), name) {
+			src = s
+			break
+		}
+	}
+	if src == nil {
+		return fmt.Errorf("%s is not the name of an available data source; use the -list flag to show them", name)
+	}
+
+	if err := sys.SetDataSources([]service.Service{src}); err != nil {
+		return err
+	}
+	if err, failed := sys.FailedDataSources()[src.String()]; failed {
+		return fmt.Errorf("%s failed to start: %v", src.String(), err)
+	}
+
+	req := &requests.DNSRequest{Name: domain, Domain: domain}
+	if !src.HandlesReq(req) {
+		return fmt.Errorf("%s does not handle subdomain queries", src.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	finished := make(chan struct{})
+	results := make(chan int, 1)
+	go printSourceResults(src, finished, results)
+
+	fmt.Fprintf(color.Error, "%s %s %s %s\n", green("Querying"), yellow(src.String()), green("for"), yellow(domain))
+	for _, in := range []interface{}{req, struct{}{}} {
+		// The requests are processed one at a time, so the second is only
+		// accepted after the data source has finished processing the query
+		select {
+		case <-ctx.Done():
+			close(finished)
+			return fmt.Errorf("%s did not finish processing the query within %v", src.String(), timeout)
+		case <-src.Done():
+			close(finished)
+			return fmt.Errorf("%s stopped while processing the query", src.String())
+		case src.Input() <- in:
+		}
+	}
+	close(finished)
+
+	total := <-results
+	fmt.Fprintf(color.Error, "%s %s\n", yellow(strconv.Itoa(total)), green("results were returned by "+src.String()))
+	return nil
+}
+
+func printSourceResults(src service.Service, finished chan struct{}, results chan int) {
+	var total int
+
+	show := func(out interface{}) {
+		total++
+		switch v := out.(type) {
+		case *requests.DNSRequest:
+			fmt.Fprintf(color.Output, "%s %s\n", green(v.Name), blue("(FQDN)"))
+		case *requests.AddrRequest:
+			fmt.Fprintf(color.Output, "%s %s\n", green(v.Address), blue("(IPAddress)"))
+		case *requests.ASNRequest:
+			fmt.Fprintf(color.Output, "%s %s %s\n", green("AS"+strconv.Itoa(v.ASN)), yellow(v.Prefix), blue("(ASN)"))
+		default:
+			fmt.Fprintf(color.Output, "%s\n", green(fmt.Sprintf("%+v", v)))
+		}
+	}
+
+	for {
+		select {
+		case out := <-src.Output():
+			show(out)
+		case <-finished:
+			// Print the results that are still buffered
+			for {
+				select {
+				case out := <-src.Output():
+					show(out)
+				default:
+					results <- total
+					return
+				}
+			}
+		}
+	}
+}
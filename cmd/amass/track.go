@@ -0,0 +1,311 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+	"github.com/owasp-amass/amass/v4/systems"
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/config/config"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+const (
+	trackUsageMsg = "track [options] -d domain"
+	// The number of seconds between the polls of the graph database made by the follow flag
+	defaultTrackInterval = 10
+)
+
+type trackArgs struct {
+	Domains  *stringset.Set
+	Interval int
+	RunTags  format.ParseTags
+	Since    string
+	Options  struct {
+		Addrs   bool
+		Follow  bool
+		JSON    bool
+		NoColor bool
+		Silent  bool
+		Verify  bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		Domains    format.ParseStrings
+	}
+}
+
+// trackedAsset is the JSON line printed for each new asset when the json flag is provided.
+type trackedAsset struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func runTrackCommand(clArgs []string) {
+	var args trackArgs
+	var help1, help2 bool
+	trackCommand := flag.NewFlagSet("track", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	trackBuf := new(bytes.Buffer)
+	trackCommand.SetOutput(trackBuf)
+
+	trackCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	trackCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	trackCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	trackCommand.IntVar(&args.Interval, "interval", defaultTrackInterval, "Number of seconds between the polls of the graph database")
+	trackCommand.Var(&args.RunTags, "run-tag", "Only show assets extracted by enumeration runs having these tags (key=value)")
+	trackCommand.StringVar(&args.Since, "since", "", "Only show the assets created since this date (YYYY-MM-DD)")
+	trackCommand.BoolVar(&args.Options.Addrs, "addrs", false, "Print the names with addresses that changed since the provided date")
+	trackCommand.BoolVar(&args.Options.Follow, "follow", false, "Keep polling and print the new assets as they are inserted")
+	trackCommand.BoolVar(&args.Options.JSON, "json", false, "Print each asset as a JSON line")
+	trackCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	trackCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	trackCommand.BoolVar(&args.Options.Verify, "verify", false, "Resolve the stored names again and report the discrepancies")
+	trackCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file. Additional details below")
+	trackCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	trackCommand.Var(&args.Filepaths.Domains, "df", "Path to a file providing root domain names")
+
+	if len(clArgs) < 1 {
+		commandUsage(trackUsageMsg, trackCommand, trackBuf)
+		return
+	}
+	if err := trackCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		commandUsage(trackUsageMsg, trackCommand, trackBuf)
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if args.Interval < 1 {
+		r.Fprintln(color.Error, "The interval must be at least one second")
+		os.Exit(1)
+	}
+	if args.Options.Verify && (args.Options.Follow || args.Since != "") {
+		r.Fprintln(color.Error, "The verify flag cannot be used with the follow and since flags")
+		os.Exit(1)
+	}
+	if args.Options.Addrs && (args.Options.Follow || args.Options.Verify) {
+		r.Fprintln(color.Error, "The addrs flag cannot be used with the follow and verify flags")
+		os.Exit(1)
+	}
+	if len(args.RunTags) > 0 && (args.Options.Addrs || args.Options.Follow || args.Options.Verify) {
+		r.Fprintln(color.Error, "The run-tag flag cannot be used with the addrs, follow and verify flags")
+		os.Exit(1)
+	}
+	if args.Options.Addrs && args.Since == "" {
+		r.Fprintln(color.Error, "The addrs flag requires the since flag")
+		os.Exit(1)
+	}
+
+	var since time.Time
+	if args.Since != "" {
+		var err error
+
+		since, err = time.Parse("2006-01-02", args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not a valid date (YYYY-MM-DD)\n", args.Since)
+			os.Exit(1)
+		}
+	}
+	if len(args.Filepaths.Domains) > 0 {
+		for _, f := range args.Filepaths.Domains {
+			list, err := config.GetListFromFile(f)
+			if err != nil {
+				r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+				return
+			}
+			args.Domains.InsertMany(list...)
+		}
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+
+	if args.Options.Verify {
+		if args.Domains.Len() == 0 {
+			r.Fprintln(color.Error, "No root domain names were provided to verify")
+			os.Exit(1)
+		}
+		runTrackVerify(cfg, &args)
+		return
+	}
+
+	db := openGraphDatabase(cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	if args.Options.Addrs {
+		trackAddrChanges(context.Background(), db, args.Domains.Slice(), since, args.Options.JSON)
+		return
+	}
+
+	var runAssets *stringset.Set
+	if len(args.RunTags) > 0 {
+		runAssets = loadTaggedRunAssets(cfg, args.RunTags)
+		if runAssets == nil {
+			return
+		}
+		defer runAssets.Close()
+	}
+	trackAssets(db, &args, runAssets, since)
+}
+
+// runTrackVerify creates the system providing the trusted resolvers and verifies the stored names,
+// until the verification completes or the user quits.
+func runTrackVerify(cfg *config.Config, args *trackArgs) {
+	sys, err := systems.NewLocalSystem(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = sys.Shutdown() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	verifyAssets(ctx, sys.GraphDatabases()[0], sys.TrustedResolvers(), args.Domains.Slice(), args.Options.JSON)
+}
+
+// trackAssets prints the assets created since the provided time. When the follow flag is provided,
+// the graph database is polled for the assets created after the last poll until the user quits.
+// When the run assets are provided, only the assets extracted by those runs are printed.
+func trackAssets(db *netmap.Graph, args *trackArgs, runAssets *stringset.Set, since time.Time) {
+	domains := args.Domains.Slice()
+	// The assets already printed, since the polls overlap
+	printed := stringset.New()
+	defer printed.Close()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	t := time.NewTicker(time.Duration(args.Interval) * time.Second)
+	defer t.Stop()
+
+	for {
+		next := time.Now()
+		for _, a := range newAssets(db, domains, runAssets, since, printed) {
+			printTrackedAsset(a, args.Options.JSON)
+		}
+		if !args.Options.Follow {
+			return
+		}
+		// Overlap the polls, so the assets committed while the last poll was made are not missed
+		since = next.Add(-time.Duration(args.Interval) * time.Second)
+
+		select {
+		case <-quit:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// newAssets returns the assets created since the provided time that have not been printed, sorted by
+// their creation time. The FQDNs are limited to the provided domains, and the assets to the run assets
+// when they are provided.
+func newAssets(db *netmap.Graph, domains []string, runAssets *stringset.Set, since time.Time, printed *stringset.Set) []*types.Asset {
+	var assets []*types.Asset
+
+	for _, atype := range []oam.AssetType{oam.FQDN, oam.IPAddress, oam.Netblock, oam.ASN, oam.RIROrg} {
+		found, err := db.DB.FindByType(atype, since.UTC())
+		if err != nil {
+			continue
+		}
+
+		for _, a := range found {
+			if printed.Has(a.ID) || (!since.IsZero() && a.CreatedAt.Before(since)) {
+				continue
+			}
+			name, ntype := assetNameAndType(a)
+			if ntype == "FQDN" && !nameInDomains(name, domains) {
+				continue
+			}
+			if runAssets != nil && !runAssets.Has(runAssetKey(name, ntype)) {
+				continue
+			}
+
+			printed.Insert(a.ID)
+			assets = append(assets, a)
+		}
+	}
+
+	sort.SliceStable(assets, func(i, j int) bool { return assets[i].CreatedAt.Before(assets[j].CreatedAt) })
+	return assets
+}
+
+func printTrackedAsset(a *types.Asset, asJSON bool) {
+	name, atype := assetNameAndType(a)
+
+	if asJSON {
+		if data, err := json.Marshal(&trackedAsset{
+			ID:        a.ID,
+			Name:      name,
+			Type:      atype,
+			CreatedAt: a.CreatedAt.UTC(),
+		}); err == nil {
+			fmt.Fprintln(color.Output, string(data))
+		}
+		return
+	}
+
+	fmt.Fprintf(color.Output, "%s %s %s\n", yellow(a.CreatedAt.Local().Format(time.RFC3339)), green(name), blue("("+atype+")"))
+}
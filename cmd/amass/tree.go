@@ -0,0 +1,164 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/amass/v4/requests"
+)
+
+const subsTreeIndent = "  "
+
+// subsTreeNode is a name in the subdomain tree. The out field is nil for the zones
+// that only exist as the parents of discovered names.
+type subsTreeNode struct {
+	name     string
+	out      *requests.Output
+	children map[string]*subsTreeNode
+}
+
+func newSubsTreeNode(name string) *subsTreeNode {
+	return &subsTreeNode{
+		name:     name,
+		children: make(map[string]*subsTreeNode),
+	}
+}
+
+// subsTree groups the discovered names under their registrable domains.
+type subsTree struct {
+	roots map[string]*subsTreeNode
+}
+
+func newSubsTree() *subsTree {
+	return &subsTree{roots: make(map[string]*subsTreeNode)}
+}
+
+// Insert adds the name of the output to the tree, creating the intermediate zones
+// between the name and its registrable domain.
+func (t *subsTree) Insert(out *requests.Output) {
+	name := strings.ToLower(strings.TrimSuffix(out.Name, "."))
+
+	apex, err := amassdns.EffectiveTLDPlusOne(name)
+	if err != nil {
+		apex = strings.ToLower(out.Domain)
+	}
+	if apex == "" || (name != apex && !strings.HasSuffix(name, "."+apex)) {
+		apex = name
+	}
+
+	node, found := t.roots[apex]
+	if !found {
+		node = newSubsTreeNode(apex)
+		t.roots[apex] = node
+	}
+
+	if name != apex {
+		labels := strings.Split(strings.TrimSuffix(name, "."+apex), ".")
+
+		zone := apex
+		for i := len(labels) - 1; i >= 0; i-- {
+			zone = labels[i] + "." + zone
+
+			child, found := node.children[zone]
+			if !found {
+				child = newSubsTreeNode(zone)
+				node.children[zone] = child
+			}
+			node = child
+		}
+	}
+	node.out = out
+}
+
+// fprintSubsTree prints the indented tree with the addresses of each discovered name when addrs is true.
+func fprintSubsTree(tree *subsTree, addrs, demo bool, outfile *os.File) {
+	for _, root := range sortedTreeNodes(tree.roots) {
+		fprintSubsTreeNode(root, 0, addrs, demo, outfile)
+	}
+}
+
+func fprintSubsTreeNode(node *subsTreeNode, depth int, addrs, demo bool, outfile *os.File) {
+	indent := strings.Repeat(subsTreeIndent, depth)
+
+	if node.out == nil {
+		name, _ := format.OutputLineParts(&requests.Output{Name: node.name}, false, demo)
+
+		fmt.Fprintf(color.Output, "%s%s\n", indent, blue(name))
+		if outfile != nil {
+			fmt.Fprintf(outfile, "%s%s\n", indent, name)
+		}
+	} else {
+		name, ips := format.OutputLineParts(node.out, addrs, demo)
+		if ips != "" {
+			ips = " " + ips
+		}
+
+		fmt.Fprintf(color.Output, "%s%s%s\n", indent, green(name), yellow(ips))
+		if outfile != nil {
+			fmt.Fprintf(outfile, "%s%s%s\n", indent, name, ips)
+		}
+	}
+
+	for _, child := range sortedTreeNodes(node.children) {
+		fprintSubsTreeNode(child, depth+1, addrs, demo, outfile)
+	}
+}
+
+// writeSubsTreeJSON prints the tree as a JSON array of the registrable domains, each holding the
+// names nested under their parent zones, censored when demo is true.
+func writeSubsTreeJSON(tree *subsTree, demo bool, outfile *os.File) {
+	records := []*format.TreeRecord{}
+	for _, root := range sortedTreeNodes(tree.roots) {
+		records = append(records, newSubsTreeRecord(root, demo))
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(color.Output, string(data))
+	if outfile != nil {
+		fmt.Fprintln(outfile, string(data))
+	}
+}
+
+func newSubsTreeRecord(node *subsTreeNode, demo bool) *format.TreeRecord {
+	rec := &format.TreeRecord{Children: []*format.TreeRecord{}}
+
+	if node.out == nil {
+		rec.Name, _ = format.OutputLineParts(&requests.Output{Name: node.name}, false, demo)
+	} else {
+		out := format.NewOutputRecord(node.out, demo)
+		rec.Name = out.Name
+		rec.Discovered = true
+		rec.Addresses = out.Addresses
+	}
+
+	for _, child := range sortedTreeNodes(node.children) {
+		rec.Children = append(rec.Children, newSubsTreeRecord(child, demo))
+	}
+	return rec
+}
+
+func sortedTreeNodes(nodes map[string]*subsTreeNode) []*subsTreeNode {
+	list := make([]*subsTreeNode, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, node)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+	return list
+}
@@ -0,0 +1,223 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+	"github.com/miekg/dns"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/resolve"
+)
+
+// The discrepancies reported by the track verify flag.
+const (
+	verifyGone    = "gone"
+	verifyChanged = "changed"
+	verifyNew     = "new"
+)
+
+// The number of names resolved at the same time by the track verify flag.
+const maxConcurrentVerifications = 50
+
+// verifyResult is the discrepancy found between the stored addresses of a name and a fresh resolution.
+type verifyResult struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Stored   []string `json:"stored,omitempty"`
+	Resolved []string `json:"resolved,omitempty"`
+}
+
+// verifyAssets resolves the in-scope names stored in the graph database again and prints the names that
+// no longer resolve, the names with addresses that changed and the names with new addresses. The records
+// confirmed by the resolution are stored again, which updates their last seen time.
+func verifyAssets(ctx context.Context, g *netmap.Graph, pool *resolve.Resolvers, domains []string, asJSON bool) {
+	stored := make(map[string][]string)
+	for _, o := range EventOutput(ctx, g, domains, time.Time{}, nil, false, nil) {
+		addrs := stored[o.Name]
+		for _, a := range o.Addresses {
+			addrs = append(addrs, a.Address.String())
+		}
+		stored[o.Name] = addrs
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var results []*verifyResult
+	var started, unresolved int
+	slots := make(chan struct{}, maxConcurrentVerifications)
+loop:
+	for name, addrs := range stored {
+		// Check the context first, since select picks randomly among the ready cases
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break loop
+		case slots <- struct{}{}:
+		}
+
+		started++
+		wg.Add(1)
+		go func(name string, addrs []string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			answers, ok := resolveName(ctx, pool, name)
+			if !ok {
+				mu.Lock()
+				unresolved++
+				mu.Unlock()
+				return
+			}
+
+			updateResolvedRecords(ctx, g, answers)
+			if res := compareAddrs(name, addrs, answers); res != nil {
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}
+		}(name, addrs)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	for _, res := range results {
+		printVerifyResult(res, asJSON)
+	}
+
+	fmt.Fprintf(color.Error, "%s %s %s %s %s\n", green("Verified"), yellow(fmt.Sprint(started-unresolved)),
+		green("names and found"), yellow(fmt.Sprint(len(results))), green("discrepancies"))
+	if unresolved > 0 {
+		fmt.Fprintf(color.Error, "%s %s\n", yellow(fmt.Sprint(unresolved)), green("names could not be resolved due to failed queries"))
+	}
+}
+
+// resolveName returns the A and AAAA answers for the name. False is returned when a query failed
+// without an answer from the resolvers, so the name cannot be considered gone.
+func resolveName(ctx context.Context, pool *resolve.Resolvers, name string) ([]*resolve.ExtractedAnswer, bool) {
+	var answers []*resolve.ExtractedAnswer
+
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		if err := amassnet.TakeDNSQuery(); err != nil {
+			return nil, false
+		}
+
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			return nil, false
+		}
+		resp, err := pool.QueryBlocking(ctx, amassdns.QueryMsg(name, qtype))
+		if err != nil || resp == nil {
+			return nil, false
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			answers = append(answers, resolve.ExtractAnswers(resp)...)
+		case dns.RcodeNameError:
+		default:
+			return nil, false
+		}
+	}
+	return answers, true
+}
+
+// updateResolvedRecords stores the records returned by the resolution, so the records already in the
+// graph database have their last seen time updated and the new addresses are added.
+func updateResolvedRecords(ctx context.Context, g *netmap.Graph, answers []*resolve.ExtractedAnswer) {
+	for _, a := range answers {
+		switch a.Type {
+		case dns.TypeCNAME:
+			_ = g.UpsertCNAME(ctx, a.Name, resolve.RemoveLastDot(a.Data))
+		case dns.TypeA:
+			_ = g.UpsertA(ctx, a.Name, a.Data)
+		case dns.TypeAAAA:
+			_ = g.UpsertAAAA(ctx, a.Name, a.Data)
+		}
+	}
+}
+
+// compareAddrs returns nil when the resolution confirmed the stored addresses of the name.
+func compareAddrs(name string, stored []string, answers []*resolve.ExtractedAnswer) *verifyResult {
+	var resolved []string
+	for _, a := range answers {
+		if a.Type == dns.TypeA || a.Type == dns.TypeAAAA {
+			resolved = append(resolved, a.Data)
+		}
+	}
+
+	stored = uniqueSorted(stored)
+	resolved = uniqueSorted(resolved)
+	res := &verifyResult{
+		Name:     name,
+		Stored:   stored,
+		Resolved: resolved,
+	}
+
+	switch {
+	case len(resolved) == 0 && len(stored) > 0:
+		res.Status = verifyGone
+	case !containsAll(resolved, stored):
+		res.Status = verifyChanged
+	case !containsAll(stored, resolved):
+		res.Status = verifyNew
+	default:
+		return nil
+	}
+	return res
+}
+
+// containsAll returns true when each of the items is found in the set.
+func containsAll(set, items []string) bool {
+	for _, item := range items {
+		if i := sort.SearchStrings(set, item); i == len(set) || set[i] != item {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueSorted(list []string) []string {
+	var unique []string
+
+	sort.Strings(list)
+	for i, item := range list {
+		if i == 0 || item != list[i-1] {
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
+
+func printVerifyResult(res *verifyResult, asJSON bool) {
+	if asJSON {
+		if data, err := json.Marshal(res); err == nil {
+			fmt.Fprintln(color.Output, string(data))
+		}
+		return
+	}
+
+	switch res.Status {
+	case verifyGone:
+		fmt.Fprintf(color.Output, "%s %s %s\n", r.Sprint(res.Status), green(res.Name), white(strings.Join(res.Stored, ",")))
+	case verifyChanged:
+		fmt.Fprintf(color.Output, "%s %s %s -> %s\n", yellow(res.Status), green(res.Name),
+			white(strings.Join(res.Stored, ",")), white(strings.Join(res.Resolved, ",")))
+	case verifyNew:
+		fmt.Fprintf(color.Output, "%s %s %s\n", blue(res.Status), green(res.Name), white(strings.Join(res.Resolved, ",")))
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/amass/v4/format"
+)
+
+const vizUsageMsg = "viz [options] -input results.ndjson -format gexf,d3,html,mermaid"
+
+type vizArgs struct {
+	Formats format.ParseStrings
+	Types   format.ParseStrings
+	Options struct {
+		Dynamic  bool
+		NoColor  bool
+		Relative bool
+		Silent   bool
+	}
+	Filepaths struct {
+		AllFilePrefix string
+		Input         string
+	}
+}
+
+func runVizCommand(clArgs []string) {
+	var args vizArgs
+	var help1, help2 bool
+	vizCommand := flag.NewFlagSet("viz", flag.ContinueOnError)
+
+	vizBuf := new(bytes.Buffer)
+	vizCommand.SetOutput(vizBuf)
+
+	vizCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	vizCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	vizCommand.BoolVar(&args.Options.Dynamic, "dynamic", false, "Add the time interval of each node and edge to the GEXF graph")
+	vizCommand.Var(&args.Formats, "format", "Visualization formats separated by commas (gexf, d3, html, mermaid)")
+	vizCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	vizCommand.BoolVar(&args.Options.Relative, "relative", false, "Load the D3 library of the HTML page from its own directory instead of the CDN")
+	vizCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	vizCommand.StringVar(&args.Filepaths.Input, "input", "", "Path to the NDJSON file written by the enum ndjson format (may be gzipped)")
+	vizCommand.StringVar(&args.Filepaths.AllFilePrefix, "oA", "", "Path prefix used for naming all output files, or - for stdout")
+	vizCommand.Var(&args.Types, "type", "Asset types kept in the graph separated by commas (can be used multiple times)")
+
+	if len(clArgs) < 1 {
+		commandUsage(vizUsageMsg, vizCommand, vizBuf)
+		return
+	}
+	if err := vizCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 || args.Filepaths.Input == "" || len(args.Formats) == 0 {
+		commandUsage(vizUsageMsg, vizCommand, vizBuf)
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	for _, f := range args.Formats {
+		if f != formatGEXF && f != formatD3 && f != formatHTML && f != formatMermaid {
+			r.Fprintf(color.Error, "%s is not a supported visualization format\n", f)
+			os.Exit(1)
+		}
+	}
+
+	if args.Options.Dynamic && !hasFormat(args.Formats, formatGEXF) {
+		r.Fprintln(color.Error, "The -dynamic flag requires the gexf format")
+		os.Exit(1)
+	}
+	if args.Options.Relative && !hasFormat(args.Formats, formatHTML) {
+		r.Fprintln(color.Error, "The -relative flag requires the html format")
+		os.Exit(1)
+	}
+
+	// The standard output can only carry a single document
+	stdout := args.Filepaths.AllFilePrefix == "-"
+	if stdout && (len(args.Formats) != 1 || args.Formats[0] == formatHTML) {
+		r.Fprintln(color.Error, "Only one of the gexf, d3 and mermaid formats can be written to stdout")
+		os.Exit(1)
+	}
+
+	types, err := parseAssetTypes(args.Types)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	prefix := args.Filepaths.AllFilePrefix
+	if prefix == "" {
+		input := strings.TrimSuffix(args.Filepaths.Input, gzipExt)
+		prefix = strings.TrimSuffix(input, filepath.Ext(input))
+	}
+
+	formats := args.Formats
+	if stdout {
+		// The accumulator only collects the graph, which is written once the input has been read
+		formats = nil
+	}
+
+	acc, err := newFormatAccumulator(prefix, formats, false)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	acc.dynamic = args.Options.Dynamic
+	acc.relative = args.Options.Relative
+
+	total, err := replayNDJSON(args.Filepaths.Input, acc, types)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to read the NDJSON file: %v\n", err)
+		os.Exit(1)
+	}
+	if err := acc.Close(); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if stdout {
+		if err := acc.WriteFormat(os.Stdout, args.Formats[0]); err != nil {
+			r.Fprintf(color.Error, "Failed to write the visualization: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(color.Error, "%s %s\n", yellow(total), green("relations were written to stdout"))
+		return
+	}
+	fmt.Fprintf(color.Error, "%s %s\n", yellow(total), green("relations were written to the visualization files"))
+}
+
+// The asset types found in the NDJSON results, as named by the from_type and to_type fields.
+var vizAssetTypes = []string{"FQDN", "IPAddress", "Netblock", "ASN", "RIROrganization"}
+
+// parseAssetTypes returns the set of asset types requested, with their names normalized. A nil map
+// is returned when no types were requested, so all the relations are kept.
+func parseAssetTypes(list []string) (map[string]struct{}, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	types := make(map[string]struct{})
+	for _, t := range list {
+		var found bool
+
+		for _, atype := range vizAssetTypes {
+			if strings.EqualFold(t, atype) {
+				types[atype] = struct{}{}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not a supported asset type; use %s", t, strings.Join(vizAssetTypes, ", "))
+		}
+	}
+	return types, nil
+}
+
+// replayNDJSON adds the relations saved in the NDJSON file to the accumulator and returns how many were added.
+// When asset types are provided, only the relations connecting two assets of those types are added.
+func replayNDJSON(path string, acc *formatAccumulator, types map[string]struct{}) (int, error) {
+	f, err := openInputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var total int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var rel assetRelation
+		if err := json.Unmarshal(data, &rel); err != nil {
+			return total, fmt.Errorf("line %d: %v", line, err)
+		}
+		if types != nil {
+			_, from := types[rel.FromType]
+			_, to := types[rel.ToType]
+			if !from || !to {
+				continue
+			}
+		}
+		// The asset IDs are not saved, so the nodes are identified by their type and name
+		rel.FromID = rel.FromType + ":" + rel.From
+		rel.ToID = rel.ToType + ":" + rel.To
+
+		acc.Add(&rel)
+		total++
+	}
+	return total, scanner.Err()
+}
@@ -0,0 +1,129 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/config/config"
+)
+
+// The headers set on each webhook request, allowing receivers to verify that the payload came from
+// this Amass instance and to reject replayed requests carrying an old timestamp.
+const (
+	webhookSignatureHeader = "X-Amass-Signature"
+	webhookTimestampHeader = "X-Amass-Timestamp"
+)
+
+// The maximum number of results posted in each webhook request.
+const maxWebhookBatch = 500
+
+// webhookSink posts the results of the enumeration to the URL provided in the notifications section
+// of the configuration options.
+type webhookSink struct {
+	URL    string
+	Secret string
+}
+
+// webhookPayload is the JSON body of each webhook request.
+type webhookPayload struct {
+	Timestamp int64            `json:"timestamp"`
+	Results   []*assetRelation `json:"results"`
+}
+
+// loadWebhookSink returns nil when the webhook has not been configured.
+func loadWebhookSink(cfg *config.Config) (*webhookSink, error) {
+	notifyRaw, ok := cfg.Options["notifications"]
+	if !ok {
+		return nil, nil
+	}
+
+	notify, ok := notifyRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("notifications is not a map[string]interface{}")
+	}
+
+	hookRaw, ok := notify["webhook"]
+	if !ok {
+		return nil, nil
+	}
+
+	hook, ok := hookRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("notifications webhook is not a map[string]interface{}")
+	}
+
+	url, ok := hook["url"].(string)
+	if !ok || url == "" {
+		return nil, fmt.Errorf("notifications webhook url is not a string")
+	}
+
+	sink := &webhookSink{URL: url}
+	if raw, found := hook["secret"]; found {
+		secret, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("notifications webhook secret is not a string")
+		}
+		sink.Secret = secret
+	}
+	return sink, nil
+}
+
+// Send posts the results to the webhook. When a secret has been configured, the request carries
+// the HMAC-SHA256 signature of the timestamp and payload.
+func (w *webhookSink) Send(ctx context.Context, results []*assetRelation) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	body, err := json.Marshal(&webhookPayload{
+		Timestamp: now,
+		Results:   results,
+	})
+	if err != nil {
+		return err
+	}
+
+	ts := strconv.FormatInt(now, 10)
+	hdr := amasshttp.Header{
+		"Content-Type":         "application/json",
+		webhookTimestampHeader: ts,
+	}
+	if w.Secret != "" {
+		hdr[webhookSignatureHeader] = "sha256=" + signWebhookPayload(w.Secret, ts, body)
+	}
+
+	resp, err := amasshttp.RequestWebPage(ctx, &amasshttp.Request{
+		URL:    w.URL,
+		Method: "POST",
+		Header: hdr,
+		Body:   string(body),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// signWebhookPayload returns the hex encoded HMAC-SHA256 of the timestamp and body, joined by a period.
+// The timestamp is signed along with the body, so it cannot be changed to replay an old request.
+func signWebhookPayload(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
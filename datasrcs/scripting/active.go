@@ -0,0 +1,92 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// The conservative defaults used when the active section of the configuration options is missing.
+const (
+	defaultMaxConcurrentAXFR = 1
+	defaultNSECWalk          = false
+)
+
+// ActiveSettings contains the values provided in the active section of the configuration options.
+type ActiveSettings struct {
+	MaxConcurrentAXFR int
+	NSECWalk          bool
+}
+
+// LoadActiveSettings returns the limits placed on the zone transfers and NSEC walking
+// performed by the active data sources.
+func LoadActiveSettings(cfg *config.Config) (*ActiveSettings, error) {
+	settings := &ActiveSettings{
+		MaxConcurrentAXFR: defaultMaxConcurrentAXFR,
+		NSECWalk:          defaultNSECWalk,
+	}
+
+	activeRaw, ok := cfg.Options["active"]
+	if !ok {
+		return settings, nil
+	}
+
+	active, ok := activeRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("active is not a map[string]interface{}")
+	}
+
+	if raw, ok := active["max_concurrent_axfr"]; ok {
+		var num int
+
+		switch v := raw.(type) {
+		case int:
+			num = v
+		case float64:
+			num = int(v)
+		default:
+			return nil, fmt.Errorf("active max_concurrent_axfr is not a number")
+		}
+		if num < 1 {
+			return nil, fmt.Errorf("active max_concurrent_axfr must be at least 1")
+		}
+		settings.MaxConcurrentAXFR = num
+	}
+	if raw, ok := active["nsec_walk"]; ok {
+		walk, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("active nsec_walk is not a bool")
+		}
+		settings.NSECWalk = walk
+	}
+	return settings, nil
+}
+
+var (
+	axfrLock  sync.Mutex
+	axfrSlots chan struct{}
+)
+
+// acquireAXFR blocks until one of the max zone transfer slots, shared by all the scripts, is available.
+// The returned function releases the slot.
+func acquireAXFR(ctx context.Context, max int) (func(), error) {
+	axfrLock.Lock()
+	if axfrSlots == nil || cap(axfrSlots) != max {
+		axfrSlots = make(chan struct{}, max)
+	}
+	slots := axfrSlots
+	axfrLock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case slots <- struct{}{}:
+	}
+	return func() { <-slots }, nil
+}
@@ -0,0 +1,31 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAcquireAXFR(t *testing.T) {
+	release, err := acquireAXFR(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Failed to acquire the first slot: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := acquireAXFR(ctx, 1); err == nil {
+		t.Errorf("A second slot was acquired beyond the maximum")
+	}
+
+	release()
+	release, err = acquireAXFR(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Failed to acquire the released slot: %v", err)
+	}
+	release()
+}
@@ -89,6 +89,14 @@ func (s *Script) config(L *lua.LState) int {
 	tb.RawSetString("recursive", lua.LBool(cfg.Recursive))
 	tb.RawSetString("min_for_recursive", lua.LNumber(cfg.MinForRecursive))
 	tb.RawSetString("max_depth", lua.LNumber(cfg.MaxDepth))
+	if settings, err := LoadPermutationSettings(cfg); err == nil {
+		perms := L.NewTable()
+		for _, strategy := range settings.Strategies {
+			perms.Append(lua.LString(strategy))
+		}
+		tb.RawSetString("permutations", perms)
+		tb.RawSetString("max_permutations", lua.LNumber(settings.MaxPermutations))
+	}
 	r.RawSetString("brute_forcing", tb)
 
 	tb = L.NewTable()
@@ -123,7 +131,8 @@ func (s *Script) dataSourceConfig(L *lua.LState) int {
 		tb.RawSetString("ttl", lua.LNumber(cfg.TTL))
 	}
 
-	if creds := dsc.GetCredentials(cfg.Name); creds != nil {
+	// Each call hands out the next account provided for the data source
+	if _, creds := s.creds.Next(cfg.Creds); creds != nil {
 		c := L.NewTable()
 
 		c.RawSetString("name", lua.LString(creds.Name))
@@ -0,0 +1,110 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// The time a set of credentials is not handed out after a request using it was rate limited.
+const credsBackoff = time.Minute
+
+// credRotator hands out the sets of credentials provided for a data source in round-robin order,
+// setting aside the sets that were rate limited.
+type credRotator struct {
+	sync.Mutex
+	next    int
+	backoff map[string]time.Time
+}
+
+func newCredRotator() *credRotator {
+	return &credRotator{backoff: make(map[string]time.Time)}
+}
+
+// Next returns the account name and the next set of credentials, ordered by the account names, skipping
+// the accounts that are backing off. When all the accounts are backing off, the account available soonest
+// is returned.
+func (cr *credRotator) Next(creds map[string]*config.Credentials) (string, *config.Credentials) {
+	cr.Lock()
+	defer cr.Unlock()
+
+	accounts := make([]string, 0, len(creds))
+	for account, c := range creds {
+		if c != nil {
+			accounts = append(accounts, account)
+		}
+	}
+	if len(accounts) == 0 {
+		return "", nil
+	}
+	sort.Strings(accounts)
+
+	now := time.Now()
+	soonest := -1
+	for i := 0; i < len(accounts); i++ {
+		idx := (cr.next + i) % len(accounts)
+		until := cr.backoff[accounts[idx]]
+
+		if !now.Before(until) {
+			soonest = idx
+			break
+		}
+		if soonest == -1 || until.Before(cr.backoff[accounts[soonest]]) {
+			soonest = idx
+		}
+	}
+
+	cr.next = (soonest + 1) % len(accounts)
+	account := accounts[soonest]
+	return account, creds[account]
+}
+
+// Backoff sets aside the credentials of the account, whose request was rate limited. False is returned
+// when no account is provided.
+func (cr *credRotator) Backoff(account string) bool {
+	if account == "" {
+		return false
+	}
+
+	cr.Lock()
+	defer cr.Unlock()
+
+	cr.backoff[account] = time.Now().Add(credsBackoff)
+	return true
+}
+
+// credsAccount returns the name of the account with the API key, secret or password contained in one
+// of the parts of a request, such as the URL, the body and the header values. The scripts place the
+// credentials handed out by Next in their requests, so the account is found from the request itself.
+func credsAccount(creds map[string]*config.Credentials, parts ...string) string {
+	accounts := make([]string, 0, len(creds))
+	for account, c := range creds {
+		if c != nil {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+
+	for _, account := range accounts {
+		c := creds[account]
+
+		for _, secret := range []string{c.Apikey, c.Secret, c.Password} {
+			if secret == "" {
+				continue
+			}
+			for _, part := range parts {
+				if strings.Contains(part, secret) {
+					return account
+				}
+			}
+		}
+	}
+	return ""
+}
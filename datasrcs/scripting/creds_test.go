@@ -0,0 +1,78 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/config/config"
+)
+
+func TestCredRotator(t *testing.T) {
+	creds := map[string]*config.Credentials{
+		"first":  {Apikey: "key1"},
+		"second": {Apikey: "key2"},
+		"third":  {Apikey: "key3"},
+	}
+
+	cr := newCredRotator()
+	if cr.Backoff("") {
+		t.Errorf("Backoff succeeded without an account")
+	}
+
+	for i, want := range []string{"key1", "key2", "key3", "key1"} {
+		if _, c := cr.Next(creds); c == nil || c.Apikey != want {
+			t.Errorf("Request %d: expected the credentials %s", i+1, want)
+		}
+	}
+
+	// The first account is rate limited after the second account has been handed out
+	if account, _ := cr.Next(creds); account != "second" {
+		t.Fatalf("Expected the second account, got %s", account)
+	}
+	if !cr.Backoff("first") {
+		t.Fatalf("Failed to back off the first account")
+	}
+	for i, want := range []string{"key3", "key2", "key3"} {
+		if _, c := cr.Next(creds); c == nil || c.Apikey != want {
+			t.Errorf("Request %d after the backoff: expected the credentials %s", i+1, want)
+		}
+	}
+
+	// When all the accounts are backing off, the account available soonest is returned
+	cr.Backoff("second")
+	cr.Backoff("third")
+	if account, c := cr.Next(creds); c == nil || account != "first" || c.Apikey != "key1" {
+		t.Errorf("Expected the credentials available soonest")
+	}
+
+	if account, c := cr.Next(nil); account != "" || c != nil {
+		t.Errorf("Credentials were returned for a data source without any")
+	}
+}
+
+func TestCredsAccount(t *testing.T) {
+	creds := map[string]*config.Credentials{
+		"first":  {Apikey: "key1"},
+		"second": {Username: "user", Password: "pass2"},
+		"third":  nil,
+	}
+
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{"key in the URL", []string{"https://api.example.com/v1?apikey=key1", ""}, "first"},
+		{"password in the auth", []string{"https://api.example.com/v1", "", "pass2"}, "second"},
+		{"no credentials", []string{"https://api.example.com/v1", "{}"}, ""},
+	}
+
+	for _, test := range tests {
+		if got := credsAccount(creds, test.parts...); got != test.want {
+			t.Errorf("%s: expected the account %q, got %q", test.name, test.want, got)
+		}
+	}
+}
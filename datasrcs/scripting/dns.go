@@ -20,7 +20,6 @@ import (
 	"github.com/owasp-amass/resolve"
 	bf "github.com/tylertreat/BoomFilters"
 	lua "github.com/yuin/gopher-lua"
-	"golang.org/x/net/publicsuffix"
 )
 
 const (
@@ -65,7 +64,7 @@ func (s *Script) resolve(L *lua.LState) int {
 	}
 
 	if detection {
-		domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+		domain, err := amassdns.EffectiveTLDPlusOne(name)
 
 		if err != nil || s.sys.TrustedResolvers().WildcardDetected(ctx, resp, domain) {
 			L.Push(lua.LNil)
@@ -92,7 +91,7 @@ func (s *Script) resolve(L *lua.LState) int {
 }
 
 func (s *Script) fwdQuery(ctx context.Context, name string, qtype uint16) (*dns.Msg, error) {
-	msg := resolve.QueryMsg(name, qtype)
+	msg := amassdns.QueryMsg(name, qtype)
 	resp, err := s.dnsQuery(ctx, msg, s.sys.Resolvers(), 5)
 	if err != nil {
 		return resp, err
@@ -116,6 +115,13 @@ func (s *Script) dnsQuery(ctx context.Context, msg *dns.Msg, r *resolve.Resolver
 		default:
 		}
 
+		if err := amassnet.TakeDNSQuery(); err != nil {
+			return nil, err
+		}
+
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			return nil, err
+		}
 		resp, err := r.QueryBlocking(ctx, msg)
 		if err != nil {
 			continue
@@ -226,7 +232,7 @@ func (s *Script) getPTR(ctx context.Context, addr string, ch chan struct{}) {
 		return
 	}
 
-	msg := resolve.ReverseMsg(addr)
+	msg := amassdns.ReverseMsg(addr)
 	resp, err := s.dnsQuery(ctx, msg, s.sys.Resolvers(), 5)
 	if err != nil || resp == nil {
 		return
@@ -270,6 +276,17 @@ func (s *Script) zoneWalk(L *lua.LState) int {
 		return 1
 	}
 
+	settings, err := LoadActiveSettings(s.sys.Config())
+	if err != nil {
+		L.Push(lua.LString(err.Error()))
+		return 1
+	}
+	// NSEC walking is only attempted when enabled by the configuration
+	if !settings.NSECWalk {
+		L.Push(lua.LNil)
+		return 1
+	}
+
 	r := resolve.NewResolvers()
 	r.SetLogger(s.sys.Config().Log)
 	_ = r.AddResolvers(15, server)
@@ -325,6 +342,21 @@ func (s *Script) wrapZoneTransfer(L *lua.LState) int {
 		return 2
 	}
 
+	settings, err := LoadActiveSettings(s.sys.Config())
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	release, err := acquireAXFR(ctx, settings.MaxConcurrentAXFR)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("the context expired before the zone transfer was attempted"))
+		return 2
+	}
+	defer release()
+
 	tb := L.NewTable()
 	if reqs, err := ZoneTransfer(ctx, name, domain, server); err == nil && len(reqs) > 0 {
 		for _, req := range reqs {
@@ -363,6 +395,9 @@ func ZoneTransfer(ctx context.Context, sub, domain, server string) ([]*requests.
 	defer cancel()
 
 	addr := net.JoinHostPort(server, "53")
+	if err := amassnet.WaitForEgress(tctx); err != nil {
+		return results, fmt.Errorf("zone xfr error: %v", err)
+	}
 	conn, err := amassnet.DialContext(tctx, "tcp", addr)
 	if err != nil {
 		return results, fmt.Errorf("zone xfr error: Failed to obtain TCP connection to [%s]: %v", addr, err)
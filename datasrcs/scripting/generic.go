@@ -0,0 +1,297 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/amass/v4/systems"
+	"github.com/owasp-amass/config/config"
+	lua "github.com/yuin/gopher-lua"
+	"gopkg.in/yaml.v3"
+)
+
+var genericNameRE = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// GenericHTTPSource is an entry of the generic_http list in the datasources file, which declares
+// a data source that requests the URL for each domain and extracts the names from the response.
+type GenericHTTPSource struct {
+	Name     string            `yaml:"name"`
+	URL      string            `yaml:"url"`
+	Header   map[string]string `yaml:"header,omitempty"`
+	JSONPath string            `yaml:"json_path,omitempty"`
+	Regex    string            `yaml:"regex,omitempty"`
+	path     []jsonPathStep
+	re       *regexp.Regexp
+}
+
+// jsonPathStep selects a key of the objects, followed by all the elements or a single element of the arrays.
+type jsonPathStep struct {
+	key   string
+	all   bool
+	index int
+}
+
+// LoadGenericHTTPSources returns the generic_http entries of the datasources file named by the datasources
+// option. Each entry requires a name, a URL containing the {domain} placeholder, and either a json_path or
+// a regex selecting the names in the response.
+func LoadGenericHTTPSources(cfg *config.Config) ([]*GenericHTTPSource, error) {
+	raw, ok := cfg.Options["datasources"]
+	if !ok {
+		return nil, nil
+	}
+
+	path, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("datasources option is not a string")
+	}
+
+	abs, err := cfg.AbsPathFromConfigDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the absolute path of the datasources file: %v", err)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the datasources file: %v", err)
+	}
+
+	var file struct {
+		GenericHTTP []*GenericHTTPSource `yaml:"generic_http"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the generic_http sources: %v", err)
+	}
+
+	seen := make(map[string]struct{})
+	for _, src := range file.GenericHTTP {
+		if err := src.validate(); err != nil {
+			return nil, err
+		}
+
+		key := strings.ToLower(src.Name)
+		if _, found := seen[key]; found {
+			return nil, fmt.Errorf("generic_http source %s was declared more than once", src.Name)
+		}
+		seen[key] = struct{}{}
+	}
+	return file.GenericHTTP, nil
+}
+
+func (src *GenericHTTPSource) validate() error {
+	if !genericNameRE.MatchString(src.Name) {
+		return fmt.Errorf("generic_http source name %q must only contain letters, digits, hyphens and underscores", src.Name)
+	}
+	if !strings.Contains(src.URL, "{domain}") {
+		return fmt.Errorf("generic_http source %s url does not contain the {domain} placeholder", src.Name)
+	}
+	if (src.JSONPath == "") == (src.Regex == "") {
+		return fmt.Errorf("generic_http source %s requires either a json_path or a regex", src.Name)
+	}
+
+	if src.JSONPath != "" {
+		path, err := parseJSONPath(src.JSONPath)
+		if err != nil {
+			return fmt.Errorf("generic_http source %s json_path: %v", src.Name, err)
+		}
+		src.path = path
+	} else {
+		re, err := regexp.Compile(src.Regex)
+		if err != nil {
+			return fmt.Errorf("generic_http source %s regex: %v", src.Name, err)
+		}
+		src.re = re
+	}
+	return nil
+}
+
+// NewGenericHTTPScript returns the data source declared by the generic_http entry, initialized, but not yet started.
+func NewGenericHTTPScript(src *GenericHTTPSource, sys systems.System) *Script {
+	script := fmt.Sprintf(`
+		name = %q
+		type = "api"
+
+		function vertical(ctx, domain)
+			generic_http(ctx, domain)
+		end
+	`, src.Name)
+
+	return newScript(script, sys, src)
+}
+
+// Requests the URL of the generic_http source for the domain and sends the names found in the response to Amass.
+func (s *Script) genericHTTP(L *lua.LState) int {
+	ctx, err := extractContext(L.CheckUserData(1))
+	if err != nil || contextExpired(ctx) {
+		return 0
+	}
+
+	src := s.generic
+	domain := L.CheckString(2)
+	vars := map[string]string{"{domain}": domain}
+
+	var account string
+	if dsc := s.sys.Config().GetDataSourceConfig(s.String()); dsc != nil {
+		// Each request uses the next account provided for the data source
+		var creds *config.Credentials
+		if account, creds = s.creds.Next(dsc.Creds); creds != nil {
+			vars["{apikey}"] = creds.Apikey
+			vars["{secret}"] = creds.Secret
+			vars["{username}"] = creds.Username
+			vars["{password}"] = creds.Password
+		}
+	}
+
+	u := expandGenericVars(src.URL, vars)
+	hdr := make(http.Header, len(src.Header))
+	for k, v := range src.Header {
+		hdr[k] = expandGenericVars(v, vars)
+	}
+
+	resp, err := s.req(ctx, account, u, "", hdr, nil, 1)
+	if err != nil || resp == nil {
+		return 0
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		s.sys.Config().Log.Printf("%s: %s: the response status was %s", s.String(), u, resp.Status)
+		return 0
+	}
+
+	var values []string
+	if src.re != nil {
+		for _, match := range src.re.FindAllStringSubmatch(resp.Body, -1) {
+			// The first capture group holds the name, when the regex has one
+			if len(match) > 1 {
+				values = append(values, match[1])
+			} else {
+				values = append(values, match[0])
+			}
+		}
+	} else {
+		var data interface{}
+		if err := json.Unmarshal([]byte(resp.Body), &data); err != nil {
+			s.sys.Config().Log.Printf("%s: %s: failed to parse the JSON response: %v", s.String(), u, err)
+			return 0
+		}
+		values = jsonPathStrings(data, src.path)
+	}
+
+	s.internalSendNames(ctx, strings.Join(values, "\n"))
+	return 0
+}
+
+// expandGenericVars replaces the placeholders in the value. Placeholders without a value are left as is.
+func expandGenericVars(value string, vars map[string]string) string {
+	for k, v := range vars {
+		if v != "" {
+			value = strings.ReplaceAll(value, k, v)
+		}
+	}
+	return value
+}
+
+// parseJSONPath accepts the subset of JSONPath made of keys separated by periods, each optionally
+// followed by [*] to select all the elements of an array or [N] to select a single element.
+// For example, $.results[*].hostname selects the hostname of each object in the results array.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var steps []jsonPathStep
+	for _, seg := range strings.Split(path, ".") {
+		key := seg
+		var selectors []string
+
+		if i := strings.Index(seg, "["); i != -1 {
+			key = seg[:i]
+			rest := seg[i:]
+
+			for rest != "" {
+				end := strings.Index(rest, "]")
+				if rest[0] != '[' || end == -1 {
+					return nil, fmt.Errorf("the segment %q has an unbalanced bracket", seg)
+				}
+				selectors = append(selectors, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		if key == "" && len(selectors) == 0 {
+			return nil, fmt.Errorf("the path %q contains an empty segment", path)
+		}
+		if strings.Contains(key, "]") {
+			return nil, fmt.Errorf("the segment %q has an unbalanced bracket", seg)
+		}
+		if key != "" {
+			steps = append(steps, jsonPathStep{key: key, index: -1})
+		}
+
+		for _, sel := range selectors {
+			if sel == "*" {
+				steps = append(steps, jsonPathStep{all: true, index: -1})
+				continue
+			}
+
+			idx, err := strconv.Atoi(sel)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("the selector [%s] is not * or an array index", sel)
+			}
+			steps = append(steps, jsonPathStep{index: idx})
+		}
+	}
+	return steps, nil
+}
+
+// jsonPathStrings returns the strings selected by the path, including the strings within the selected arrays.
+func jsonPathStrings(data interface{}, path []jsonPathStep) []string {
+	nodes := []interface{}{data}
+
+	for _, step := range path {
+		var next []interface{}
+
+		for _, node := range nodes {
+			switch {
+			case step.key != "":
+				if obj, ok := node.(map[string]interface{}); ok {
+					if v, found := obj[step.key]; found {
+						next = append(next, v)
+					}
+				}
+			case step.all:
+				if arr, ok := node.([]interface{}); ok {
+					next = append(next, arr...)
+				}
+			default:
+				if arr, ok := node.([]interface{}); ok && step.index < len(arr) {
+					next = append(next, arr[step.index])
+				}
+			}
+		}
+		nodes = next
+	}
+
+	var values []string
+	for _, node := range nodes {
+		switch v := node.(type) {
+		case string:
+			values = append(values, v)
+		case []interface{}:
+			for _, elem := range v {
+				if str, ok := elem.(string); ok {
+					values = append(values, str)
+				}
+			}
+		}
+	}
+	return values
+}
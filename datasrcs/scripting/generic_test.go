@@ -0,0 +1,193 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/config/config"
+)
+
+func writeDataSourcesFile(t *testing.T, cfg *config.Config, content string) {
+	path := filepath.Join(t.TempDir(), "datasources.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write the datasources file: %v", err)
+	}
+	cfg.Options["datasources"] = path
+}
+
+func TestLoadGenericHTTPSources(t *testing.T) {
+	cfg := config.NewConfig()
+
+	if srcs, err := LoadGenericHTTPSources(cfg); err != nil || len(srcs) != 0 {
+		t.Errorf("Sources were returned without the datasources option: %v", err)
+	}
+
+	writeDataSourcesFile(t, cfg, `
+datasources:
+  - name: AlienVault
+    creds:
+      account:
+        apikey: secret
+generic_http:
+  - name: JSONSource
+    url: "https://api.example.com/v1/{domain}/subdomains"
+    header:
+      Authorization: "Bearer {apikey}"
+    json_path: "$.results[*].hostname"
+  - name: RegexSource
+    url: "https://example.com/search?q={domain}"
+    regex: 'host="([^"]+)"'
+`)
+	srcs, err := LoadGenericHTTPSources(cfg)
+	if err != nil {
+		t.Fatalf("Failed to load the generic_http sources: %v", err)
+	}
+	if len(srcs) != 2 || srcs[0].Name != "JSONSource" || srcs[1].Name != "RegexSource" {
+		t.Fatalf("The generic_http sources were not returned in order")
+	}
+	if srcs[0].Header["Authorization"] != "Bearer {apikey}" || len(srcs[0].path) != 3 || srcs[1].re == nil {
+		t.Errorf("The generic_http sources were not parsed: %+v %+v", srcs[0], srcs[1])
+	}
+
+	for _, bad := range []string{
+		"generic_http:\n  - name: \"bad name\"\n    url: \"https://example.com/{domain}\"\n    regex: \".*\"\n",
+		"generic_http:\n  - name: NoDomain\n    url: \"https://example.com/\"\n    regex: \".*\"\n",
+		"generic_http:\n  - name: NoExtraction\n    url: \"https://example.com/{domain}\"\n",
+		"generic_http:\n  - name: Both\n    url: \"https://example.com/{domain}\"\n    regex: \".*\"\n    json_path: \"$.names\"\n",
+		"generic_http:\n  - name: BadRegex\n    url: \"https://example.com/{domain}\"\n    regex: \"([\"\n",
+		"generic_http:\n  - name: BadPath\n    url: \"https://example.com/{domain}\"\n    json_path: \"$.names[x]\"\n",
+		"generic_http:\n  - name: Twice\n    url: \"https://example.com/{domain}\"\n    regex: \".*\"\n  - name: twice\n    url: \"https://example.com/{domain}\"\n    regex: \".*\"\n",
+	} {
+		writeDataSourcesFile(t, cfg, bad)
+		if _, err := LoadGenericHTTPSources(cfg); err == nil {
+			t.Errorf("The datasources file did not return an error:\n%s", bad)
+		}
+	}
+}
+
+func TestJSONPathStrings(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{
+		"names": ["a.owasp.org", "b.owasp.org"],
+		"results": [
+			{"hostname": "c.owasp.org", "ip": "192.0.2.1"},
+			{"hostname": "d.owasp.org"},
+			{"ip": "192.0.2.2"}
+		],
+		"nested": {"list": [["e.owasp.org"], ["f.owasp.org"]]}
+	}`), &data); err != nil {
+		t.Fatalf("Failed to parse the test document: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"$.names", []string{"a.owasp.org", "b.owasp.org"}},
+		{"names[*]", []string{"a.owasp.org", "b.owasp.org"}},
+		{"$.names[1]", []string{"b.owasp.org"}},
+		{"$.results[*].hostname", []string{"c.owasp.org", "d.owasp.org"}},
+		{"$.nested.list[*][0]", []string{"e.owasp.org", "f.owasp.org"}},
+		{"$.results[5].hostname", nil},
+		{"$.missing", nil},
+	}
+
+	for _, test := range tests {
+		path, err := parseJSONPath(test.path)
+		if err != nil {
+			t.Errorf("Failed to parse the path %s: %v", test.path, err)
+			continue
+		}
+		if got := jsonPathStrings(data, path); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Path %s: expected %v, got %v", test.path, test.expected, got)
+		}
+	}
+
+	for _, bad := range []string{"$.names[", "$.names[-1]", "$..names", "$.names]"} {
+		if _, err := parseJSONPath(bad); err == nil {
+			t.Errorf("The path %s did not return an error", bad)
+		}
+	}
+}
+
+func TestGenericHTTPScript(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"results": [{"hostname": "www.owasp.org"}, {"hostname": "api.owasp.org"}, {"hostname": "www.example.com"}]}`)
+	}))
+	defer ts.Close()
+
+	cfg := config.NewConfig()
+	writeDataSourcesFile(t, cfg, fmt.Sprintf(`
+datasources:
+  - name: JSONSource
+    creds:
+      account:
+        apikey: key1
+generic_http:
+  - name: JSONSource
+    url: "%s/{domain}"
+    header:
+      Authorization: "Bearer {apikey}"
+    json_path: "$.results[*].hostname"
+`, ts.URL))
+	srcs, err := LoadGenericHTTPSources(cfg)
+	if err != nil || len(srcs) != 1 {
+		t.Fatalf("Failed to load the generic_http source: %v", err)
+	}
+	cfg.DataSrcConfigs = &config.DataSourceConfig{
+		Datasources: []*config.DataSource{{
+			Name:  "JSONSource",
+			Creds: map[string]*config.Credentials{"account": {Apikey: "key1"}},
+		}},
+	}
+
+	sys := newMockSystem(cfg)
+	defer func() { _ = sys.Shutdown() }()
+
+	s := NewGenericHTTPScript(srcs[0], sys)
+	if s == nil {
+		t.Fatal("Failed to create the generic_http data source")
+	}
+	if err := sys.AddAndStart(s); err != nil {
+		t.Fatalf("Failed to start the generic_http data source: %v", err)
+	}
+
+	domain := "owasp.org"
+	cfg.AddDomain(domain)
+	s.Input() <- &requests.DNSRequest{Domain: domain}
+
+	expected := stringset.New("www.owasp.org", "api.owasp.org")
+	defer expected.Close()
+
+	timer := time.NewTimer(10 * time.Second)
+	defer timer.Stop()
+	for expected.Len() > 0 {
+		select {
+		case <-timer.C:
+			t.Fatalf("The names %v were not returned", expected.Slice())
+		case req := <-s.Output():
+			if d, ok := req.(*requests.DNSRequest); !ok || !expected.Has(d.Name) || d.Domain != domain {
+				t.Errorf("Unexpected output: %v", req)
+			} else {
+				expected.Remove(d.Name)
+			}
+		}
+	}
+}
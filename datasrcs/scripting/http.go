@@ -6,6 +6,7 @@ package scripting
 
 import (
 	"context"
+	"errors"
 	"net/url"
 	"strings"
 	"time"
@@ -57,10 +58,11 @@ func (s *Script) request(L *lua.LState) int {
 
 	id, _ := getStringField(L, opt, "id")
 	pass, _ := getStringField(L, opt, "pass")
-	resp, err := s.req(ctx, url, body, hdr, &http.BasicAuth{
+	attempts, _ := getNumberField(L, opt, "max_attempts")
+	resp, err := s.req(ctx, "", url, body, hdr, &http.BasicAuth{
 		Username: id,
 		Password: pass,
-	})
+	}, int(attempts))
 
 	if err != nil || resp == nil {
 		L.Push(lua.LNil)
@@ -169,12 +171,13 @@ func (s *Script) scrape(L *lua.LState) int {
 
 	id, _ := getStringField(L, opt, "id")
 	pass, _ := getStringField(L, opt, "pass")
+	attempts, _ := getNumberField(L, opt, "max_attempts")
 
 	sucess := lua.LFalse
-	if resp, err := s.req(ctx, url, body, hdr, &http.BasicAuth{
+	if resp, err := s.req(ctx, "", url, body, hdr, &http.BasicAuth{
 		Username: id,
 		Password: pass,
-	}); err == nil {
+	}, int(attempts)); err == nil {
 		if resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 400 {
 			if num := s.internalSendNames(ctx, resp.Body); num > 0 {
 				sucess = lua.LTrue
@@ -188,29 +191,57 @@ func (s *Script) scrape(L *lua.LState) int {
 	return 1
 }
 
-func (s *Script) req(ctx context.Context, url, data string, hdr http.Header, auth *http.BasicAuth) (*http.Response, error) {
+// req sends the request, and retries the rate limited responses when more than one attempt is allowed.
+// The account is the name of the credentials used by the request, which is found from the request
+// when it is not provided.
+func (s *Script) req(ctx context.Context, account, url, data string, hdr http.Header, auth *http.BasicAuth, attempts int) (*http.Response, error) {
 	method := "GET"
 	if data != "" {
 		method = "POST"
 	}
+	if attempts < 1 {
+		attempts = 1
+	}
 
 	numRateLimitChecks(s, s.seconds)
-	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(attempts)*20*time.Second)
 	defer cancel()
 
-	resp, err := http.RequestWebPage(ctx, &http.Request{
+	req := &http.Request{
 		URL:    url,
 		Method: method,
 		Header: hdr,
 		Body:   data,
 		Auth:   auth,
-	})
-	if err != nil {
-		cfg := s.sys.Config()
+	}
 
+	var err error
+	var resp *http.Response
+	if attempts > 1 {
+		policy := http.DefaultRetryPolicy
+		policy.MaxAttempts = attempts
+		resp, err = http.RequestWebPageWithRetry(ctx, req, &policy)
+	} else {
+		resp, err = http.RequestWebPage(ctx, req)
+	}
+	cfg := s.sys.Config()
+	// Responses exceeding the max_response_bytes setting are always logged
+	if errors.Is(err, http.ErrResponseTooLarge) {
+		cfg.Log.Printf("%s: %s: %v", s.String(), url, err)
+	} else if err != nil {
 		if cfg.Verbose {
 			cfg.Log.Printf("%s: %s: %v", s.String(), url, err)
 		}
+	} else if resp.Truncated {
+		cfg.Log.Printf("%s: %s: the response body was truncated at the maximum size", s.String(), url)
+	}
+	// Rate limited credentials are set aside, so the other accounts of the data source are used
+	if err == nil && resp.StatusCode == 429 && account == "" {
+		account = s.requestAccount(url, data, hdr, auth)
+	}
+	if err == nil && resp.StatusCode == 429 && s.creds.Backoff(account) {
+		cfg.Log.Printf("%s: %s: the credentials of the %s account were rate limited and will not be used for %v",
+			s.String(), url, account, credsBackoff)
 	}
 	return resp, err
 }
@@ -257,3 +288,20 @@ func (s *Script) crawl(L *lua.LState) int {
 	}
 	return 0
 }
+
+// requestAccount returns the name of the account with the credentials carried by the request.
+func (s *Script) requestAccount(url, data string, hdr http.Header, auth *http.BasicAuth) string {
+	dsc := s.sys.Config().GetDataSourceConfig(s.String())
+	if dsc == nil {
+		return ""
+	}
+
+	parts := []string{url, data}
+	for _, v := range hdr {
+		parts = append(parts, v)
+	}
+	if auth != nil {
+		parts = append(parts, auth.Password)
+	}
+	return credsAccount(dsc.Creds, parts...)
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/owasp-amass/resolve"
 	bf "github.com/tylertreat/BoomFilters"
 	lua "github.com/yuin/gopher-lua"
-	"golang.org/x/net/publicsuffix"
 )
 
 func (s *Script) newNameWithContext(ctx context.Context, name string) {
@@ -141,7 +140,7 @@ func (s *Script) newPTR(ctx context.Context, record *resolve.ExtractedAnswer) {
 	}
 
 	ptr := strings.ToLower(resolve.RemoveLastDot(record.Name))
-	domain, err := publicsuffix.EffectiveTLDPlusOne(ptr)
+	domain, err := amassdns.EffectiveTLDPlusOne(ptr)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,86 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"fmt"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// The permutation strategies that can be listed in the bruteforce section of the configuration options.
+const (
+	PermutationNumeric = "numeric"
+	PermutationPrefix  = "prefix"
+	PermutationTLDSwap = "tld_swap"
+)
+
+// The maximum number of names generated by the permutations of each name, unless max_permutations is provided.
+const defaultMaxPermutations = 100
+
+// PermutationSettings contains the permutations and max_permutations values provided in the bruteforce
+// section of the configuration options.
+type PermutationSettings struct {
+	Strategies      []string
+	MaxPermutations int
+}
+
+// LoadPermutationSettings returns the permutation strategies applied by the brute forcing script to the
+// resolved names, in the order they were listed.
+func LoadPermutationSettings(cfg *config.Config) (*PermutationSettings, error) {
+	settings := &PermutationSettings{MaxPermutations: defaultMaxPermutations}
+
+	bruteRaw, ok := cfg.Options["bruteforce"]
+	if !ok {
+		return settings, nil
+	}
+
+	brute, ok := bruteRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bruteforce is not a map[string]interface{}")
+	}
+
+	if raw, ok := brute["permutations"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bruteforce permutations is not an array")
+		}
+
+		seen := make(map[string]struct{})
+		for _, item := range list {
+			strategy, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("bruteforce permutations item is not a string")
+			}
+
+			switch strategy {
+			case PermutationNumeric, PermutationPrefix, PermutationTLDSwap:
+			default:
+				return nil, fmt.Errorf("bruteforce permutations item %s must be numeric, prefix or tld_swap", strategy)
+			}
+			if _, found := seen[strategy]; !found {
+				seen[strategy] = struct{}{}
+				settings.Strategies = append(settings.Strategies, strategy)
+			}
+		}
+	}
+	if raw, ok := brute["max_permutations"]; ok {
+		var num int
+
+		switch v := raw.(type) {
+		case int:
+			num = v
+		case float64:
+			num = int(v)
+		default:
+			return nil, fmt.Errorf("bruteforce max_permutations is not a number")
+		}
+		if num < 1 {
+			return nil, fmt.Errorf("bruteforce max_permutations must be at least 1")
+		}
+		settings.MaxPermutations = num
+	}
+	return settings, nil
+}
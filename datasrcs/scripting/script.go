@@ -47,12 +47,18 @@ type Script struct {
 	cbsLock    sync.Mutex
 	subre      *regexp.Regexp
 	seconds    int
+	creds      *credRotator
+	generic    *GenericHTTPSource
 	ctx        context.Context
 	cancel     context.CancelFunc
 }
 
 // NewScript returns the object initialized, but not yet started.
 func NewScript(script string, sys systems.System) *Script {
+	return newScript(script, sys, nil)
+}
+
+func newScript(script string, sys systems.System, generic *GenericHTTPSource) *Script {
 	re, err := regexp.Compile(dns.AnySubdomainRegexString())
 	if err != nil {
 		return nil
@@ -64,6 +70,8 @@ func NewScript(script string, sys systems.System) *Script {
 		stop:     make(chan struct{}, 1),
 		sys:      sys,
 		subre:    re,
+		creds:    newCredRotator(),
+		generic:  generic,
 	}
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	L := s.newLuaState(sys.Config())
@@ -132,6 +140,9 @@ func (s *Script) newLuaState(cfg *config.Config) *lua.LState {
 	L.SetGlobal("set_rate_limit", L.NewFunction(s.setRateLimit))
 	L.SetGlobal("check_rate_limit", L.NewFunction(s.checkRateLimit))
 	L.SetGlobal("subdomain_regex", lua.LString(dns.AnySubdomainRegexString()))
+	if s.generic != nil {
+		L.SetGlobal("generic_http", L.NewFunction(s.genericHTTP))
+	}
 	return L
 }
 
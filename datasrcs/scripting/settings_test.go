@@ -0,0 +1,88 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package scripting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/owasp-amass/config/config"
+)
+
+func TestLoadSettings(t *testing.T) {
+	tests := []struct {
+		option   string
+		load     func(*config.Config) (interface{}, error)
+		defaults interface{}
+		provided map[string]interface{}
+		expected interface{}
+		bad      []map[string]interface{}
+	}{
+		{
+			option: "active",
+			load:   func(cfg *config.Config) (interface{}, error) { return LoadActiveSettings(cfg) },
+			defaults: &ActiveSettings{
+				MaxConcurrentAXFR: defaultMaxConcurrentAXFR,
+				NSECWalk:          defaultNSECWalk,
+			},
+			provided: map[string]interface{}{
+				"max_concurrent_axfr": 4,
+				"nsec_walk":           true,
+			},
+			expected: &ActiveSettings{MaxConcurrentAXFR: 4, NSECWalk: true},
+			bad: []map[string]interface{}{
+				{"max_concurrent_axfr": 0},
+				{"max_concurrent_axfr": "two"},
+				{"nsec_walk": "yes"},
+			},
+		},
+		{
+			option:   "bruteforce",
+			load:     func(cfg *config.Config) (interface{}, error) { return LoadPermutationSettings(cfg) },
+			defaults: &PermutationSettings{MaxPermutations: defaultMaxPermutations},
+			provided: map[string]interface{}{
+				"permutations":     []interface{}{"tld_swap", "numeric", "tld_swap"},
+				"max_permutations": 25,
+			},
+			expected: &PermutationSettings{
+				Strategies:      []string{PermutationTLDSwap, PermutationNumeric},
+				MaxPermutations: 25,
+			},
+			bad: []map[string]interface{}{
+				{"permutations": "numeric"},
+				{"permutations": []interface{}{"reverse"}},
+				{"permutations": []interface{}{7}},
+				{"max_permutations": 0},
+				{"max_permutations": "ten"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := config.NewConfig()
+
+		settings, err := test.load(cfg)
+		if err != nil {
+			t.Errorf("%s: failed to load the default settings: %v", test.option, err)
+		} else if !reflect.DeepEqual(settings, test.defaults) {
+			t.Errorf("%s: expected the default settings %+v, got %+v", test.option, test.defaults, settings)
+		}
+
+		cfg.Options[test.option] = test.provided
+		settings, err = test.load(cfg)
+		if err != nil {
+			t.Errorf("%s: failed to load the settings: %v", test.option, err)
+		} else if !reflect.DeepEqual(settings, test.expected) {
+			t.Errorf("%s: expected the settings %+v, got %+v", test.option, test.expected, settings)
+		}
+
+		for _, bad := range test.bad {
+			cfg.Options[test.option] = bad
+			if _, err := test.load(cfg); err == nil {
+				t.Errorf("%s: the settings %v did not return an error", test.option, bad)
+			}
+		}
+	}
+}
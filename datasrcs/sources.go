@@ -26,6 +26,16 @@ func GetAllSources(sys systems.System) []service.Service {
 		}
 	}
 
+	generic, err := scripting.LoadGenericHTTPSources(sys.Config())
+	if err != nil {
+		sys.Config().Log.Printf("Failed to load the generic_http data sources: %v", err)
+	}
+	for _, src := range generic {
+		if s := scripting.NewGenericHTTPScript(src, sys); s != nil {
+			srvs = append(srvs, s)
+		}
+	}
+
 	sort.Slice(srvs, func(i, j int) bool {
 		return srvs[i].String() < srvs[j].String()
 	})
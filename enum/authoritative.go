@@ -0,0 +1,144 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/resolve"
+)
+
+// The number of attempts made by the trusted resolvers for the NS and address queries of the discovery.
+const authDiscoveryAttempts = 5
+
+// The time allowed for an authoritative server to answer the sanity check.
+const authCheckTimeout = 3 * time.Second
+
+// authServers holds a resolver pool of the authoritative servers discovered for each root domain name.
+type authServers struct {
+	sync.Mutex
+	zones map[string]*resolve.Resolvers
+}
+
+func newAuthServers() *authServers {
+	return &authServers{zones: make(map[string]*resolve.Resolvers)}
+}
+
+// Discover queries the NS records of each zone using the trusted resolvers and creates a pool of the
+// authoritative servers that pass the sanity check. The zones without such servers are skipped.
+func (as *authServers) Discover(ctx context.Context, e *Enumeration, zones []string) {
+	for _, zone := range zones {
+		addrs := authServerAddrs(ctx, e, zone)
+		if len(addrs) == 0 {
+			e.Config.Log.Printf("No authoritative servers passed the sanity check for %s", zone)
+			continue
+		}
+
+		pool := resolve.NewResolvers()
+		pool.SetLogger(e.Config.Log)
+		_ = pool.AddResolvers(e.Config.ResolversQPS, addrs...)
+		pool.SetTimeout(3 * time.Second)
+
+		as.Lock()
+		as.zones[zone] = pool
+		as.Unlock()
+		e.Config.Log.Printf("Using the authoritative servers %s for names within %s", strings.Join(addrs, ", "), zone)
+	}
+}
+
+// Pool returns the pool of the authoritative servers for the deepest zone containing the name,
+// or nil when the name is not within a zone having authoritative servers.
+func (as *authServers) Pool(name string) *resolve.Resolvers {
+	if as == nil {
+		return nil
+	}
+
+	as.Lock()
+	defer as.Unlock()
+
+	var zone string
+	var pool *resolve.Resolvers
+	for z, p := range as.zones {
+		if (name == z || strings.HasSuffix(name, "."+z)) && len(z) > len(zone) {
+			zone = z
+			pool = p
+		}
+	}
+	return pool
+}
+
+// Stop releases the resolver pools.
+func (as *authServers) Stop() {
+	if as == nil {
+		return
+	}
+
+	as.Lock()
+	defer as.Unlock()
+
+	for zone, pool := range as.zones {
+		pool.Stop()
+		delete(as.zones, zone)
+	}
+}
+
+// authServerAddrs returns the addresses of the authoritative servers of the zone that pass the sanity check.
+func authServerAddrs(ctx context.Context, e *Enumeration, zone string) []string {
+	resp, err := e.dnsQuery(ctx, zone, dns.TypeNS, e.Sys.TrustedResolvers(), authDiscoveryAttempts)
+	if err != nil || resp == nil {
+		return nil
+	}
+
+	var addrs []string
+	for _, ns := range resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeNS) {
+		server := strings.ToLower(resolve.RemoveLastDot(ns.Data))
+
+		for _, qtype := range e.fwdTypes[1:] {
+			resp, err := e.dnsQuery(ctx, server, qtype, e.Sys.TrustedResolvers(), authDiscoveryAttempts)
+			if err != nil || resp == nil {
+				continue
+			}
+
+			for _, a := range resolve.AnswersByType(resolve.ExtractAnswers(resp), qtype) {
+				addr := net.JoinHostPort(a.Data, "53")
+
+				if authSanityCheck(ctx, e, addr, zone) {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+	}
+	return addrs
+}
+
+// authSanityCheck returns true when the server provides an authoritative answer for the SOA record of the zone.
+// The query is counted against the DNS budget and waits for the egress limiter like the other queries.
+func authSanityCheck(ctx context.Context, e *Enumeration, addr, zone string) bool {
+	ctx, cancel := context.WithTimeout(ctx, authCheckTimeout)
+	defer cancel()
+
+	if err := e.takeDNSQuery(); err != nil {
+		return false
+	}
+
+	e.breaker.Wait(ctx)
+	if err := amassnet.WaitForEgress(ctx); err != nil {
+		return false
+	}
+
+	client := dns.Client{Net: "udp"}
+	resp, _, err := client.ExchangeContext(ctx, amassdns.QueryMsg(zone, dns.TypeSOA), addr)
+	if err != nil || resp == nil || resp.Rcode != dns.RcodeSuccess || !resp.Authoritative {
+		return false
+	}
+	return len(resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeSOA)) > 0
+}
@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// BlacklistRegexPrefix distinguishes the scope blacklist entries providing a regular expression
+// from the entries matched as subdomain suffixes.
+const BlacklistRegexPrefix = "re:"
+
+// BlacklistPatterns compiles the regular expressions provided by the scope blacklist. An error is
+// returned for the first entry that is not a valid regular expression.
+func BlacklistPatterns(cfg *config.Config) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, entry := range cfg.Scope.Blacklist {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasPrefix(entry, BlacklistRegexPrefix) {
+			continue
+		}
+
+		expr := strings.TrimPrefix(entry, BlacklistRegexPrefix)
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("blacklist contains an invalid regular expression %q: %v", expr, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// blacklisted returns true when the name matches a subdomain suffix or a regular expression of the blacklist.
+func (e *Enumeration) blacklisted(name string) bool {
+	if e.Config.Blacklisted(name) {
+		return true
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	for _, re := range e.blPatterns {
+		if re.MatchString(n) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// The defaults used when the dns section of the configuration options does not set the timeout_threshold
+// and timeout_backoff. A threshold of zero disables the circuit breaker.
+const (
+	defaultTimeoutThreshold = 0.5
+	defaultTimeoutBackoff   = 30 * time.Second
+	// The number of responses required before the timeout rate is evaluated
+	minBreakerSamples = 200
+)
+
+// circuitBreaker pauses new DNS queries when the aggregate timeout rate across the resolvers exceeds
+// the threshold, so a transient loss of the network does not burn through the resolver pool.
+type circuitBreaker struct {
+	sync.Mutex
+	log       *log.Logger
+	threshold float64
+	backoff   time.Duration
+	responses int
+	timeouts  int
+	until     time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		threshold: defaultTimeoutThreshold,
+		backoff:   defaultTimeoutBackoff,
+	}
+}
+
+// SetSettings applies the settings and the logger used to report each pause.
+func (cb *circuitBreaker) SetSettings(settings *breakerSettings, logger *log.Logger) {
+	cb.Lock()
+	defer cb.Unlock()
+
+	cb.log = logger
+	cb.threshold = settings.Threshold
+	cb.backoff = settings.Backoff
+}
+
+// Record adds the outcome of a single query to the current sample. When the sample is large enough and
+// the timeout rate exceeds the threshold, new queries are paused for the backoff interval.
+func (cb *circuitBreaker) Record(timeout bool) {
+	if cb == nil {
+		return
+	}
+
+	cb.Lock()
+	defer cb.Unlock()
+
+	if cb.threshold <= 0 {
+		return
+	}
+	// Outcomes of the queries sent before the pause are not held against the next sample
+	if time.Now().Before(cb.until) {
+		return
+	}
+
+	cb.responses++
+	if timeout {
+		cb.timeouts++
+	}
+	if cb.responses < minBreakerSamples {
+		return
+	}
+
+	if rate := float64(cb.timeouts) / float64(cb.responses); rate > cb.threshold {
+		cb.until = time.Now().Add(cb.backoff)
+		if cb.log != nil {
+			cb.log.Printf("DNS resolution paused for %v after %.0f%% of the last %d queries timed out",
+				cb.backoff, rate*100, cb.responses)
+		}
+	}
+	cb.responses = 0
+	cb.timeouts = 0
+}
+
+// Wait blocks while new queries are paused.
+func (cb *circuitBreaker) Wait(ctx context.Context) {
+	if cb == nil {
+		return
+	}
+
+	cb.Lock()
+	delay := time.Until(cb.until)
+	cb.Unlock()
+
+	if delay <= 0 {
+		return
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
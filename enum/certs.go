@@ -0,0 +1,124 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"sync"
+
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/amass/v4/requests"
+)
+
+// The number of addresses having their certificates pulled at once, unless cert_concurrency is set
+const defaultCertPulls = 50
+
+// certPuller pulls the TLS certificates of the in-scope addresses and submits the names found
+// within them, so the addresses lead to the hostnames they serve.
+type certPuller struct {
+	sync.Mutex
+	enum   *Enumeration
+	ports  []int
+	pulled map[string]struct{}
+	slots  chan struct{}
+}
+
+func newCertPuller(e *Enumeration, max int) *certPuller {
+	if max <= 0 {
+		max = defaultCertPulls
+	}
+
+	ports := e.Config.Scope.Ports
+	if len(ports) == 0 {
+		ports = []int{443}
+	}
+
+	return &certPuller{
+		enum:   e,
+		ports:  ports,
+		pulled: make(map[string]struct{}),
+		slots:  make(chan struct{}, max),
+	}
+}
+
+// Pull requests the certificates of the address once, in the background.
+func (p *certPuller) Pull(addr string) {
+	if p == nil || !p.mark(addr) {
+		return
+	}
+
+	go func() {
+		if p.acquire() {
+			p.pull(addr)
+		}
+	}()
+}
+
+// mark returns true the first time the address is provided.
+func (p *certPuller) mark(addr string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, found := p.pulled[addr]; found {
+		return false
+	}
+	p.pulled[addr] = struct{}{}
+	return true
+}
+
+// acquire waits for a free slot and returns false when the enumeration ends first.
+func (p *certPuller) acquire() bool {
+	select {
+	case <-p.enum.ctx.Done():
+		return false
+	case p.slots <- struct{}{}:
+	}
+	return true
+}
+
+// pull releases the slot acquired by the caller once the certificates have been pulled.
+func (p *certPuller) pull(addr string) {
+	defer func() { <-p.slots }()
+
+	for _, name := range amasshttp.PullCertificateNames(p.enum.ctx, addr, p.ports, 0) {
+		n := amasshttp.CleanName(name)
+		// The names outside of the scope are not resolved
+		if domain := p.enum.Config.WhichDomain(n); domain != "" {
+			p.enum.nameSrc.newName(&requests.DNSRequest{
+				Name:   n,
+				Domain: domain,
+			})
+		}
+	}
+}
+
+// submitScopeAddresses pulls the certificates of the addresses and the IPv4 netblocks in the configuration
+// scope. The addresses are submitted as the slots become free, so large netblocks do not start a goroutine per host.
+func (p *certPuller) submitScopeAddresses() {
+	var addrs []string
+	for _, addr := range p.enum.Config.Scope.Addresses {
+		addrs = append(addrs, addr.String())
+	}
+	for _, cidr := range p.enum.Config.Scope.CIDRs {
+		// Skip IPv6 netblocks, since they are simply too large
+		if ip := cidr.IP.Mask(cidr.Mask); amassnet.IsIPv6(ip) {
+			continue
+		}
+
+		for _, addr := range amassnet.AllHosts(cidr) {
+			addrs = append(addrs, addr.String())
+		}
+	}
+
+	for _, addr := range addrs {
+		if !p.mark(addr) {
+			continue
+		}
+		if !p.acquire() {
+			return
+		}
+		go p.pull(addr)
+	}
+}
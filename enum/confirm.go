@@ -0,0 +1,101 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+	"time"
+
+	"github.com/caffix/pipeline"
+	"github.com/miekg/dns"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/resolve"
+)
+
+// confirmTask is the pipeline stage that checks resolved names against the confirm resolvers.
+// Names that fail confirmation are flagged in the log, but continue through the pipeline.
+type confirmTask struct {
+	enum      *Enumeration
+	resolvers []*resolve.Resolvers
+	threshold int
+}
+
+func newConfirmTask(e *Enumeration, settings *confirmSettings) *confirmTask {
+	ct := &confirmTask{
+		enum:      e,
+		threshold: settings.Threshold,
+	}
+
+	for _, addr := range settings.Resolvers {
+		// Each confirm resolver receives its own pool, so the answers can be counted per resolver
+		r := resolve.NewResolvers()
+		if err := r.AddResolvers(e.Config.TrustedQPS, addr); err != nil || r.Len() == 0 {
+			e.Config.Log.Printf("Failed to add %s as a confirm resolver", addr)
+			continue
+		}
+
+		r.SetLogger(e.Config.Log)
+		r.SetTimeout(2 * time.Second)
+		ct.resolvers = append(ct.resolvers, r)
+	}
+	return ct
+}
+
+func (ct *confirmTask) stop() {
+	for _, r := range ct.resolvers {
+		r.Stop()
+	}
+}
+
+// Process implements the pipeline Task interface.
+func (ct *confirmTask) Process(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+	}
+
+	if len(ct.resolvers) == 0 {
+		return data, nil
+	}
+
+	if req, ok := data.(*requests.DNSRequest); ok && req != nil && len(req.Records) > 0 {
+		qtype := uint16(req.Records[0].Type)
+
+		start := time.Now()
+		num := ct.confirmations(ctx, req.Name, qtype)
+		ct.enum.perf.Add(PerfConfirm, time.Since(start))
+		if num < ct.threshold {
+			ct.enum.Config.Log.Printf("Flagged %s: only %d of %d confirm resolvers provided an answer",
+				req.Name, num, len(ct.resolvers))
+		}
+	}
+	return data, nil
+}
+
+// confirmations returns the number of confirm resolvers that provided an answer for the name.
+func (ct *confirmTask) confirmations(ctx context.Context, name string, qtype uint16) int {
+	var num int
+
+	for _, r := range ct.resolvers {
+		if err := ct.enum.takeDNSQuery(); err != nil {
+			break
+		}
+
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			break
+		}
+		resp, err := r.QueryBlocking(ctx, amassdns.QueryMsg(name, qtype))
+		if err != nil || resp == nil || resp.Rcode != dns.RcodeSuccess {
+			continue
+		}
+		if len(resolve.ExtractAnswers(resp)) > 0 {
+			num++
+		}
+	}
+	return num
+}
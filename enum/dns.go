@@ -15,6 +15,8 @@ import (
 	"github.com/caffix/pipeline"
 	"github.com/caffix/queue"
 	"github.com/miekg/dns"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/resolve"
 )
@@ -33,10 +35,32 @@ var FwdQueryTypes = []uint16{
 	dns.TypeAAAA,
 }
 
-var fwdQueryTypesLookup = map[uint16]int{dns.TypeCNAME: 0, dns.TypeA: 1, dns.TypeAAAA: 2}
+// fwdQueryTypes returns the DNS record types queried for a discovered name using the address family.
+func fwdQueryTypes(family string) []uint16 {
+	switch family {
+	case AddressFamilyIPv4:
+		return []uint16{dns.TypeCNAME, dns.TypeA}
+	case AddressFamilyIPv6:
+		return []uint16{dns.TypeCNAME, dns.TypeAAAA}
+	case AddressFamilyPrefer6:
+		return []uint16{dns.TypeCNAME, dns.TypeAAAA, dns.TypeA}
+	}
+	return FwdQueryTypes
+}
+
+// nextFwdType returns the record type queried after qtype, or false when no types remain.
+func (e *Enumeration) nextFwdType(qtype uint16) (uint16, bool) {
+	for i, t := range e.fwdTypes {
+		if t == qtype && i+1 < len(e.fwdTypes) {
+			return e.fwdTypes[i+1], true
+		}
+	}
+	return 0, false
+}
 
 type req struct {
 	Ctx        context.Context
+	Start      time.Time
 	Data       pipeline.Data
 	Qtype      uint16
 	Attempts   int
@@ -44,6 +68,10 @@ type req struct {
 	InScope    bool
 	Sent       bool
 	HasRecords bool
+	// Auth is true when the last query was sent to the authoritative servers of the name
+	Auth bool
+	// NoAuth is true once the authoritative servers failed to answer for the name
+	NoAuth bool
 }
 
 // dnsTask is the task that handles all DNS name resolution requests within the pipeline.
@@ -169,18 +197,21 @@ func (dt *dnsTask) Process(ctx context.Context, data pipeline.Data, tp pipeline.
 	})
 
 	if v, ok := data.(*requests.DNSRequest); ok {
-		qtype := FwdQueryTypes[0]
-		msg := resolve.QueryMsg(v.Name, qtype)
+		qtype := dt.enum.fwdTypes[0]
+		msg := amassdns.QueryMsg(v.Name, qtype)
 		k := key(msg.Id, msg.Question[0].Name)
 
-		if dt.addReqWithIncrement(k, &req{
+		entry := &req{
 			Ctx:        ctx,
+			Start:      time.Now(),
 			Data:       data.Clone(),
 			Qtype:      qtype,
 			Attempts:   1,
 			HasRecords: len(v.Records) > 0,
-		}) {
-			dt.pool.Query(ctx, msg, dt.resps)
+		}
+
+		if dt.addReqWithIncrement(k, entry) {
+			dt.query(ctx, msg, entry)
 		} else {
 			dt.enum.Config.Log.Printf("Failed to enter %s into the request registry on the %s DNS task", msg.Question[0].Name, dt.trust)
 		}
@@ -200,7 +231,7 @@ func (dt *dnsTask) nextStage(ctx context.Context, data pipeline.Data) {
 
 	stage := "validate"
 	if dt.trusted {
-		stage = "store"
+		stage = "confirm"
 	}
 
 	pipeline.SendData(ctx, stage, data, params)
@@ -252,10 +283,30 @@ func (dt *dnsTask) delReq(key string) *req {
 	return nil
 }
 
+// pendingNames returns the names of the requests that are still waiting for a response.
+func (dt *dnsTask) pendingNames() []*requests.DNSRequest {
+	dt.Lock()
+	defer dt.Unlock()
+
+	var names []*requests.DNSRequest
+	for _, entry := range dt.reqs {
+		if v, ok := entry.Data.(*requests.DNSRequest); ok {
+			names = append(names, v)
+		}
+	}
+	return names
+}
+
 func (dt *dnsTask) delReqWithDecrement(key string) {
 	if req := dt.delReq(key); req != nil {
 		dt.release <- struct{}{}
 
+		stage := PerfDNSUntrusted
+		if dt.trusted {
+			stage = PerfDNSTrusted
+		}
+		dt.enum.perf.Add(stage, time.Since(req.Start))
+
 		if !req.Sent && (req.InScope || req.HasRecords) {
 			dt.nextStage(req.Ctx, req.Data)
 		}
@@ -279,6 +330,7 @@ func (dt *dnsTask) processResponses() {
 
 func (dt *dnsTask) processResp(resp *dns.Msg) {
 	k := key(resp.Id, resp.Question[0].Name)
+	dt.enum.breaker.Record(resp.Rcode == resolve.RcodeNoResponse)
 
 	entry := dt.getReq(k)
 	if entry == nil {
@@ -301,6 +353,10 @@ func (dt *dnsTask) processResp(resp *dns.Msg) {
 	case dns.RcodeRefused:
 		entry.Servfails++
 	}
+	// the authoritative servers are not queried again for the name after an error
+	if entry.Auth && resp.Rcode != dns.RcodeSuccess {
+		entry.NoAuth = true
+	}
 
 	ctx := entry.Ctx
 	qtype := resp.Question[0].Qtype
@@ -308,6 +364,9 @@ func (dt *dnsTask) processResp(resp *dns.Msg) {
 
 	select {
 	case <-ctx.Done():
+		if v, ok := entry.Data.(*requests.DNSRequest); ok {
+			dt.enum.failed.Write(v.Name, v.Domain, FailedInterrupted)
+		}
 		dt.delReqWithDecrement(k)
 		return
 	default:
@@ -318,13 +377,40 @@ func (dt *dnsTask) processResp(resp *dns.Msg) {
 		if resp.Rcode == dns.RcodeSuccess {
 			dt.processFwdRequest(ctx, resp, name, qtype, v, entry)
 		} else {
-			go dt.retry(resolve.QueryMsg(v.Name, qtype), resp.Id, entry)
+			go dt.retry(amassdns.QueryMsg(v.Name, qtype), resp.Id, entry)
 		}
 	default:
 		dt.delReqWithDecrement(k)
 	}
 }
 
+// query sends the DNS message once the circuit breaker and the global egress rate limit allow it.
+// The untrusted task sends the message to the authoritative servers of the name when available.
+func (dt *dnsTask) query(ctx context.Context, msg *dns.Msg, entry *req) {
+	pool := dt.pool
+
+	entry.Auth = false
+	if !dt.trusted && !entry.NoAuth {
+		name := strings.ToLower(resolve.RemoveLastDot(msg.Question[0].Name))
+
+		if p := dt.enum.auth.Pool(name); p != nil {
+			pool = p
+			entry.Auth = true
+		}
+	}
+
+	// the request remains pending, so it is recorded as interrupted once the enumeration winds down
+	if err := dt.enum.takeDNSQuery(); err != nil {
+		return
+	}
+
+	dt.enum.breaker.Wait(ctx)
+	if err := amassnet.WaitForEgress(ctx); err != nil {
+		return
+	}
+	pool.Query(ctx, msg, dt.resps)
+}
+
 func (dt *dnsTask) retry(msg *dns.Msg, id uint16, entry *req) {
 	k := key(id, msg.Question[0].Name)
 
@@ -333,9 +419,16 @@ func (dt *dnsTask) retry(msg *dns.Msg, id uint16, entry *req) {
 		dt.delReq(k)
 		dt.addReq(key(msg.Id, msg.Question[0].Name), entry)
 		time.Sleep(resolve.TruncatedExponentialBackoff(entry.Attempts-1, initialBackoffDelay, maximumBackoffDelay))
-		dt.pool.Query(entry.Ctx, msg, dt.resps)
+		dt.query(entry.Ctx, msg, entry)
 	} else {
 		dt.enum.Config.Log.Printf("%s was dropped after failing to resolve %d times on the %s DNS task", msg.Question[0].Name, entry.Attempts-1, dt.trust)
+		if v, ok := entry.Data.(*requests.DNSRequest); ok {
+			reason := FailedTimeout
+			if entry.Servfails >= maxRcodeServerFails {
+				reason = FailedServfail
+			}
+			dt.enum.failed.Write(v.Name, v.Domain, reason)
+		}
 		dt.delReqWithDecrement(k)
 	}
 }
@@ -343,14 +436,14 @@ func (dt *dnsTask) retry(msg *dns.Msg, id uint16, entry *req) {
 func (dt *dnsTask) nextType(ctx context.Context, name string, id, qtype uint16, entry *req) {
 	k := key(id, name)
 
-	if idx, found := fwdQueryTypesLookup[qtype]; found && idx+1 < len(FwdQueryTypes) {
+	if next, found := dt.enum.nextFwdType(qtype); found {
 		entry.Attempts = 1
 		entry.Servfails = 0
-		entry.Qtype = FwdQueryTypes[idx+1]
-		msg := resolve.QueryMsg(name, entry.Qtype)
+		entry.Qtype = next
+		msg := amassdns.QueryMsg(name, entry.Qtype)
 		dt.delReq(k)
 		dt.addReq(key(msg.Id, msg.Question[0].Name), entry)
-		dt.pool.Query(ctx, msg, dt.resps)
+		dt.query(ctx, msg, entry)
 	} else {
 		dt.delReqWithDecrement(k)
 	}
@@ -358,6 +451,12 @@ func (dt *dnsTask) nextType(ctx context.Context, name string, id, qtype uint16,
 
 func (dt *dnsTask) processFwdRequest(ctx context.Context, resp *dns.Msg, name string, qtype uint16, req *requests.DNSRequest, entry *req) {
 	ans := resolve.ExtractAnswers(resp)
+	// a referral from the authoritative servers means the name was delegated to another zone
+	if len(ans) == 0 && entry.Auth && !resp.Authoritative {
+		entry.NoAuth = true
+		go dt.retry(amassdns.QueryMsg(name, qtype), resp.Id, entry)
+		return
+	}
 	if len(ans) == 0 {
 		dt.nextType(ctx, name, resp.Id, qtype, entry)
 		return
@@ -382,10 +481,12 @@ func (dt *dnsTask) processFwdRequest(ctx context.Context, resp *dns.Msg, name st
 		return
 	}
 
-	req.Records = append(req.Records, convertAnswers(rr)...)
+	req.Records = append(req.Records, convertAnswers(resp, rr)...)
 	entry.HasRecords = len(req.Records) > 0
-	// are there additional record types to query for?
-	if idx, found := fwdQueryTypesLookup[qtype]; found && qtype != dns.TypeCNAME && idx+1 < len(FwdQueryTypes) {
+	// are there additional record types to query for? The A records are
+	// not needed when IPv6 is preferred and AAAA records have been found
+	prefer6 := dt.enum.family == AddressFamilyPrefer6 && qtype == dns.TypeAAAA
+	if _, found := dt.enum.nextFwdType(qtype); found && qtype != dns.TypeCNAME && !prefer6 {
 		dt.nextType(ctx, name, resp.Id, qtype, entry)
 		return
 	}
@@ -425,7 +526,7 @@ func (dt *dnsTask) queryNS(ctx context.Context, name, domain string, ch chan []r
 						Domain: domain,
 						Server: record.Data,
 					}, tp)
-					records = append(records, convertAnswers([]*resolve.ExtractedAnswer{record})...)
+					records = append(records, convertAnswers(resp, []*resolve.ExtractedAnswer{record})...)
 				}
 
 				ch <- records
@@ -441,7 +542,7 @@ func (dt *dnsTask) queryMX(ctx context.Context, name string, ch chan []requests.
 	if resp, err := dt.enum.dnsQuery(ctx, name, dns.TypeMX, dt.enum.Sys.TrustedResolvers(), maxDNSQueryAttempts); err == nil {
 		if ans := resolve.ExtractAnswers(resp); len(ans) > 0 {
 			if rr := resolve.AnswersByType(ans, dns.TypeMX); len(rr) > 0 {
-				ch <- convertAnswers(rr)
+				ch <- convertAnswers(resp, rr)
 				return
 			}
 		}
@@ -459,7 +560,7 @@ func (dt *dnsTask) querySOA(ctx context.Context, name string, ch chan []requests
 				for _, a := range rr {
 					pieces := strings.Split(a.Data, ",")
 					a.Data = pieces[len(pieces)-1]
-					records = append(records, convertAnswers([]*resolve.ExtractedAnswer{a})...)
+					records = append(records, convertAnswers(resp, []*resolve.ExtractedAnswer{a})...)
 				}
 				ch <- records
 			}
@@ -473,7 +574,7 @@ func (dt *dnsTask) querySPF(ctx context.Context, name string, ch chan []requests
 	if resp, err := dt.enum.dnsQuery(ctx, name, dns.TypeSPF, dt.enum.Sys.TrustedResolvers(), maxDNSQueryAttempts); err == nil {
 		if ans := resolve.ExtractAnswers(resp); len(ans) > 0 {
 			if rr := resolve.AnswersByType(ans, dns.TypeSPF); len(rr) > 0 {
-				ch <- convertAnswers(rr)
+				ch <- convertAnswers(resp, rr)
 				return
 			}
 		}
@@ -498,7 +599,7 @@ func (e *Enumeration) fwdQuery(ctx context.Context, name string, qtype uint16) (
 }
 
 func (e *Enumeration) dnsQuery(ctx context.Context, name string, qtype uint16, r *resolve.Resolvers, attempts int) (*dns.Msg, error) {
-	msg := resolve.QueryMsg(name, qtype)
+	msg := amassdns.QueryMsg(name, qtype)
 
 	for num := 0; num < attempts; num++ {
 		select {
@@ -507,6 +608,14 @@ func (e *Enumeration) dnsQuery(ctx context.Context, name string, qtype uint16, r
 		default:
 		}
 
+		if err := e.takeDNSQuery(); err != nil {
+			return nil, err
+		}
+
+		e.breaker.Wait(ctx)
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			return nil, err
+		}
 		resp, err := r.QueryBlocking(ctx, msg)
 		if err != nil {
 			continue
@@ -525,16 +634,22 @@ func (e *Enumeration) dnsQuery(ctx context.Context, name string, qtype uint16, r
 }
 
 func (e *Enumeration) wildcardDetected(ctx context.Context, req *requests.DNSRequest, resp *dns.Msg) bool {
-	return e.Sys.TrustedResolvers().WildcardDetected(ctx, resp, req.Domain)
+	if !e.wildcards.WildcardDetected(ctx, resp, req.Domain) {
+		return false
+	}
+
+	e.dropped.Write(req.Name, wildcardParent(req.Name, req.Domain), respAddrs(resp))
+	return true
 }
 
-func convertAnswers(ans []*resolve.ExtractedAnswer) []requests.DNSAnswer {
+func convertAnswers(resp *dns.Msg, ans []*resolve.ExtractedAnswer) []requests.DNSAnswer {
 	var answers []requests.DNSAnswer
 
 	for _, a := range ans {
 		answers = append(answers, requests.DNSAnswer{
 			Name: a.Name,
 			Type: int(a.Type),
+			TTL:  answerTTL(resp, a),
 			Data: a.Data,
 		})
 	}
@@ -6,6 +6,8 @@ package enum
 
 import (
 	"context"
+	"regexp"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 	"github.com/caffix/queue"
 	"github.com/caffix/service"
 	"github.com/owasp-amass/amass/v4/datasrcs"
+	"github.com/owasp-amass/amass/v4/datasrcs/scripting"
+	"github.com/owasp-amass/amass/v4/intel"
+	amassnet "github.com/owasp-amass/amass/v4/net"
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/amass/v4/systems"
 	"github.com/owasp-amass/config/config"
@@ -23,60 +28,303 @@ import (
 
 // Enumeration is the object type used to execute a DNS enumeration.
 type Enumeration struct {
-	Config   *config.Config
-	Sys      systems.System
-	ctx      context.Context
-	graph    *netmap.Graph
-	srcs     []service.Service
-	done     chan struct{}
-	nameSrc  *enumSource
-	subTask  *subdomainTask
-	dnsTask  *dnsTask
-	valTask  *dnsTask
-	store    *dataManager
-	requests queue.Queue
-	plock    sync.Mutex
-	pending  bool
+	Config *config.Config
+	Sys    systems.System
+	// RunID identifies the run in the markers recorded for the names that could not be resolved
+	RunID string
+	// NamesOnly restricts the enumeration to the names provided by the configuration, without
+	// submitting the root domain names, the ASNs and the names already in the graph database
+	NamesOnly bool
+	// AuthoritativeResolvers sends the queries for the names within each root domain name to the
+	// authoritative servers of the domain before the untrusted resolvers
+	AuthoritativeResolvers bool
+	// CertNames pulls the TLS certificates of the in-scope addresses in the active mode, and resolves
+	// the names found within them that are in scope
+	CertNames bool
+	// Registrars looks up the registrar of each root domain name from RDAP in the active mode, and
+	// records it in the output directory for the subs -apex output
+	Registrars bool
+
+	ctx           context.Context
+	cancel        context.CancelFunc
+	budgetOnce    sync.Once
+	graph         *netmap.Graph
+	srcs          []service.Service
+	domains       []string
+	done          chan struct{}
+	nameSrc       *enumSource
+	subTask       *subdomainTask
+	dnsTask       *dnsTask
+	valTask       *dnsTask
+	confirm       *confirmTask
+	store         *dataManager
+	dropped       *droppedLog
+	wildcards     wildcardDetector
+	records       *recordLog
+	failed        *failedLog
+	registrars    *registrarLog
+	auth          *authServers
+	prober        *httpProber
+	certs         *certPuller
+	filter        *nameFilter
+	blPatterns    []*regexp.Regexp
+	sources       *sourceTracker
+	wordlists     *wordlistTracker
+	perf          *perfTimers
+	breaker       *circuitBreaker
+	progress      *progressTracker
+	postProcs     []PostProcessor
+	requests      queue.Queue
+	plock         sync.Mutex
+	pending       bool
+	active        []string
+	deterministic bool
+	maxSources    int
+	infra         bool
+	family        string
+	fwdTypes      []uint16
 }
 
 // NewEnumeration returns an initialized Enumeration that has not been started yet.
 func NewEnumeration(cfg *config.Config, sys systems.System, graph *netmap.Graph) *Enumeration {
 	return &Enumeration{
-		Config:   cfg,
-		Sys:      sys,
-		graph:    graph,
-		srcs:     datasrcs.SelectedDataSources(cfg, sys.DataSources()),
-		requests: queue.NewQueue(),
+		Config:    cfg,
+		Sys:       sys,
+		graph:     graph,
+		srcs:      datasrcs.SelectedDataSources(cfg, sys.DataSources()),
+		sources:   newSourceTracker(),
+		wordlists: newWordlistTracker(),
+		perf:      newPerfTimers(),
+		breaker:   newCircuitBreaker(),
+		progress:  newProgressTracker(),
+		requests:  queue.NewQueue(),
 	}
 }
 
+// NewChunk returns an Enumeration that only submits the provided subset of the root domain names in
+// the configuration scope, so large domain lists can be enumerated in chunks sharing the same system.
+func (e *Enumeration) NewChunk(domains []string) *Enumeration {
+	chunk := NewEnumeration(e.Config, e.Sys, e.graph)
+	chunk.domains = domains
+	chunk.RunID = e.RunID
+	chunk.NamesOnly = e.NamesOnly
+	chunk.AuthoritativeResolvers = e.AuthoritativeResolvers
+	chunk.CertNames = e.CertNames
+	chunk.Registrars = e.Registrars
+	chunk.sources = e.sources
+	chunk.wordlists = e.wordlists
+	chunk.perf = e.perf
+	chunk.breaker = e.breaker
+	chunk.progress = e.progress
+	chunk.postProcs = e.postProcs
+	return chunk
+}
+
+// Stop winds down the enumeration and its running chunks. No new work is dispatched, the names already
+// discovered are still stored, and Start returns without an error. The chunks started afterward return immediately.
+func (e *Enumeration) Stop() {
+	e.progress.stop()
+}
+
+// Stopped returns true once Stop has been called on the enumeration or one of its chunks.
+func (e *Enumeration) Stopped() bool {
+	return e.progress.isStopped()
+}
+
+// Confidence returns the score of the name, computed from the reputation of the data sources that
+// reported it. False is returned when the name was not reported by any data source.
+func (e *Enumeration) Confidence(name string) (float64, bool) {
+	return e.sources.Score(name)
+}
+
+// Sources returns the names of the data sources that reported the name, sorted alphabetically.
+func (e *Enumeration) Sources(name string) []string {
+	return e.sources.Sources(name)
+}
+
+// WordlistLabel returns the label of the bruteforce labeled wordlist that provided the name.
+// False is returned when the name was not brute forced using a labeled wordlist.
+func (e *Enumeration) WordlistLabel(name string) (string, bool) {
+	return e.wordlists.Label(name)
+}
+
+// PerfReport returns the breakdown of where the time of the enumeration, including its chunks, was spent.
+func (e *Enumeration) PerfReport() *PerfReport {
+	return e.perf.Report()
+}
+
+func (e *Enumeration) rootDomains() []string {
+	if len(e.domains) > 0 {
+		return e.domains
+	}
+	return e.Config.Domains()
+}
+
 // Start begins the vertical domain correlation process.
 func (e *Enumeration) Start(ctx context.Context) error {
 	e.done = make(chan struct{})
 	defer close(e.done)
+	if e.Stopped() {
+		return nil
+	}
+
+	// The configuration can be shared by concurrent enumerations
+	e.Config.Lock()
+	err := e.Config.CheckSettings()
+	e.Config.Unlock()
+	if err != nil {
+		return err
+	}
+
+	wildcards, err := loadWildcardSettings(e.Config)
+	if err != nil {
+		return err
+	}
+	if wildcards.LogDropped != "" {
+		e.dropped, err = newDroppedLog(wildcards.LogDropped)
+		if err != nil {
+			return err
+		}
+		defer e.dropped.Close()
+	}
+	e.wildcards = e.Sys.TrustedResolvers()
+	if wildcards.Probes > 0 {
+		e.wildcards = newProbeDetector(e, wildcards)
+	}
+
+	filters, err := loadNameFilterSettings(e.Config)
+	if err != nil {
+		return err
+	}
+	e.filter = newNameFilter(filters)
 
-	if err := e.Config.CheckSettings(); err != nil {
+	e.blPatterns, err = BlacklistPatterns(e.Config)
+	if err != nil {
+		return err
+	}
+
+	confirms, err := loadConfirmSettings(e.Config)
+	if err != nil {
+		return err
+	}
+
+	e.infra, err = systems.InfrastructureEnabled(e.Config)
+	if err != nil {
+		return err
+	}
+
+	e.family, err = AddressFamily(e.Config)
+	if err != nil {
+		return err
+	}
+	e.fwdTypes = fwdQueryTypes(e.family)
+
+	breaker, err := loadBreakerSettings(e.Config)
+	if err != nil {
+		return err
+	}
+	e.breaker.SetSettings(breaker, e.Config.Log)
+
+	record, err := loadRecordAnswers(e.Config)
+	if err != nil {
+		return err
+	}
+	if record {
+		e.records, err = newRecordLog(DNSRecordsFilepath(e.Config))
+		if err != nil {
+			return err
+		}
+		defer e.records.Close()
+	}
+
+	if e.RunID != "" {
+		e.failed, err = newFailedLog(FailedNamesFilepath(e.Config), e.RunID)
+		if err != nil {
+			return err
+		}
+		defer e.failed.Close()
+	}
+
+	if e.Registrars && e.Config.Active && !e.NamesOnly {
+		e.registrars, err = newRegistrarLog(RegistrarsFilepath(e.Config))
+		if err != nil {
+			return err
+		}
+		defer e.registrars.Close()
+	}
+
+	probe, err := loadHTTPProbeSettings(e.Config)
+	if err != nil {
+		return err
+	}
+	if probe.Enabled {
+		e.prober, err = newHTTPProber(ctx, HTTPProbeFilepath(e.Config), probe)
+		if err != nil {
+			return err
+		}
+		defer e.prober.Close()
+	}
+
+	// Check the settings used by the scripts before the data sources use them
+	if _, err := scripting.LoadActiveSettings(e.Config); err != nil {
+		return err
+	}
+	if _, err := scripting.LoadPermutationSettings(e.Config); err != nil {
+		return err
+	}
+
+	weights, err := loadSourceReputation(e.Config)
+	if err != nil {
+		return err
+	}
+	e.sources.SetWeights(weights)
+
+	if e.Config.BruteForcing {
+		lists, err := loadLabeledWordlists(e.Config)
+		if err != nil {
+			return err
+		}
+		if err := e.wordlists.Load(e.Config, lists); err != nil {
+			return err
+		}
+	}
+
+	e.maxSources, err = loadMaxSourcesPerName(e.Config)
+	if err != nil {
+		return err
+	}
+
+	e.deterministic, err = loadDeterministicSources(e.Config)
+	if err != nil {
 		return err
 	}
 	// This context, used throughout the enumeration, will provide the
 	// ability to pass the configuration and event bus to all the components
-	var cancel context.CancelFunc
-	e.ctx, cancel = context.WithCancel(ctx)
-	defer cancel()
+	e.ctx, e.cancel = context.WithCancel(ctx)
+	defer e.cancel()
 	go e.manageDataSrcRequests()
 
+	if e.AuthoritativeResolvers {
+		e.auth = newAuthServers()
+		defer e.auth.Stop()
+		e.auth.Discover(e.ctx, e, e.rootDomains())
+	}
+
 	e.dnsTask = newDNSTask(e, false)
 	e.valTask = newDNSTask(e, true)
+	e.confirm = newConfirmTask(e, confirms)
 	e.store = newDataManager(e)
 	e.subTask = newSubdomainTask(e)
 	defer e.subTask.Stop()
 	defer e.dnsTask.stop()
 	defer e.valTask.stop()
+	defer e.confirm.stop()
 
 	var stages []pipeline.Stage
 	stages = append(stages, pipeline.FIFO("root", e.valTask.rootTaskFunc()))
 	stages = append(stages, pipeline.FIFO("dns", e.dnsTask))
 	stages = append(stages, pipeline.FIFO("validate", e.valTask))
+	stages = append(stages, pipeline.DynamicPool("confirm", e.confirm, 50))
 	stages = append(stages, pipeline.FIFO("store", e.store))
 	stages = append(stages, pipeline.FIFO("", e.subTask))
 
@@ -84,26 +332,72 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	// The pipeline input source will receive all the names
 	e.nameSrc = newEnumSource(p, e)
 	defer e.nameSrc.Stop()
+	e.progress.track(e)
+	defer e.progress.untrack(e)
 
-	e.submitASNs()
-	e.submitDomainNames()
+	if e.CertNames && e.Config.Active {
+		// The cert_concurrency option is shared with the active mode of the intel subcommand
+		max, err := intel.CertConcurrency(e.Config)
+		if err != nil {
+			return err
+		}
+
+		e.certs = newCertPuller(e, max)
+		go e.certs.submitScopeAddresses()
+	}
+
+	if !e.NamesOnly {
+		e.submitASNs()
+		e.submitDomainNames()
+	}
+	if e.registrars != nil {
+		e.registrars.Lookup(e.ctx, e.rootDomains())
+	}
 	/*
 	 * Now that the pipeline input source has been setup, names provided
 	 * by the user and names acquired from the graph database can be brought
 	 * into the enumeration
 	 */
-	go e.submitKnownNames()
+	if !e.NamesOnly {
+		go e.submitKnownNames()
+	}
 	go e.submitProvidedNames()
 
-	err := p.ExecuteBuffered(e.ctx, e.nameSrc, e.makeOutputSink(), 50)
+	err = p.ExecuteBuffered(e.ctx, e.nameSrc, e.makeOutputSink(), 50)
+	// The names still being resolved did not complete during this run
+	for _, dt := range []*dnsTask{e.dnsTask, e.valTask} {
+		for _, req := range dt.pendingNames() {
+			e.failed.Write(req.Name, req.Domain, FailedInterrupted)
+		}
+	}
 	// Ensure all data has been stored
 	<-e.store.Stop()
+	if e.Stopped() {
+		// The cancellation requested by Stop is not a failure of the enumeration
+		return nil
+	}
+	return err
+}
+
+// takeDNSQuery counts a DNS query against the budget shared by the process. Once the budget has been
+// used, the enumeration context is canceled, so no new DNS work is dispatched while the names
+// already discovered are still stored.
+func (e *Enumeration) takeDNSQuery() error {
+	err := amassnet.TakeDNSQuery()
+	if err != nil {
+		e.budgetOnce.Do(func() {
+			e.Config.Log.Printf("dns_budget_exhausted: no more DNS queries are issued after %d queries", amassnet.DNSQueries())
+			if e.cancel != nil {
+				e.cancel()
+			}
+		})
+	}
 	return err
 }
 
 // Release the root domain names to the input source and each data source.
 func (e *Enumeration) submitDomainNames() {
-	for _, domain := range e.Config.Domains() {
+	for _, domain := range e.rootDomains() {
 		req := &requests.DNSRequest{
 			Name:   domain,
 			Domain: domain,
@@ -152,20 +446,34 @@ loop:
 				continue loop
 			}
 
-			for name := range nameToSrc {
+			if e.sourceCapReached(element) {
+				continue loop
+			}
+
+			var ready []service.Service
+			for _, name := range e.srcNames(nameToSrc) {
 				if src := nameToSrc[name]; src != nil && src.HandlesReq(element) {
 					if len(requestsMap[name]) == 0 && !pending[name] {
-						go e.fireRequest(src, element, finished)
+						ready = append(ready, src)
 						pending[name] = true
 					} else {
 						requestsMap[name] = append(requestsMap[name], element)
 					}
 				}
 			}
+			if len(ready) > 0 {
+				e.setActiveSources(pending)
+				e.fireRequests(ready, element, finished)
+			}
 		case name := <-finished:
+			// Drop the queued requests for names already reported by enough data sources
+			for len(requestsMap[name]) > 0 && e.sourceCapReached(requestsMap[name][0]) {
+				requestsMap[name] = requestsMap[name][1:]
+			}
 			if len(requestsMap[name]) == 0 {
 				pending[name] = false
 				e.setRequestsPending(pending)
+				e.setActiveSources(pending)
 				continue loop
 			}
 
@@ -176,6 +484,40 @@ loop:
 	e.requests.Process(func(e interface{}) {})
 }
 
+// sourceCapReached returns true when the request is for a name that has already been reported by the
+// number of data sources set by max_sources_per_name. Requests for the root domain names are never capped.
+func (e *Enumeration) sourceCapReached(element interface{}) bool {
+	if e.maxSources <= 0 {
+		return false
+	}
+
+	var name, domain string
+	switch v := element.(type) {
+	case *requests.ResolvedRequest:
+		name, domain = v.Name, v.Domain
+	case *requests.SubdomainRequest:
+		name, domain = v.Name, v.Domain
+	default:
+		return false
+	}
+
+	return name != domain && e.sources.Count(name) >= e.maxSources
+}
+
+// srcNames returns the data source names in the order the requests are sent to them.
+// When deterministic_sources is enabled, the data sources are always ordered alphabetically.
+func (e *Enumeration) srcNames(nameToSrc map[string]service.Service) []string {
+	names := make([]string, 0, len(nameToSrc))
+	for name := range nameToSrc {
+		names = append(names, name)
+	}
+
+	if e.deterministic {
+		sort.Strings(names)
+	}
+	return names
+}
+
 func (e *Enumeration) requestsPending() bool {
 	e.plock.Lock()
 	defer e.plock.Unlock()
@@ -198,7 +540,26 @@ func (e *Enumeration) setRequestsPending(p map[string]bool) {
 	e.plock.Unlock()
 }
 
+// fireRequests sends the request to the data sources without blocking the caller. When deterministic_sources
+// is enabled, the data sources receive the request one after another, in the order provided.
+func (e *Enumeration) fireRequests(srcs []service.Service, req interface{}, finished chan string) {
+	if !e.deterministic {
+		for _, src := range srcs {
+			go e.fireRequest(src, req, finished)
+		}
+		return
+	}
+
+	go func() {
+		for _, src := range srcs {
+			e.fireRequest(src, req, finished)
+		}
+	}()
+}
+
 func (e *Enumeration) fireRequest(srv service.Service, req interface{}, finished chan string) {
+	e.perf.SourceRequest(srv.String())
+
 	select {
 	case <-e.done:
 	case <-e.ctx.Done():
@@ -221,7 +582,7 @@ func (e *Enumeration) submitKnownNames() {
 }
 
 func (e *Enumeration) readNamesFromDatabase(db *netmap.Graph) {
-	for _, d := range e.Config.Domains() {
+	for _, d := range e.rootDomains() {
 		assets, err := db.DB.FindByScope([]oam.Asset{domain.FQDN{Name: d}}, time.Time{})
 		if err != nil {
 			continue
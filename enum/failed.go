@@ -0,0 +1,122 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// FailedNamesFilename is the file in the output directory where the names that could not be
+// resolved by each enumeration run are recorded, so a later run can retry them.
+const FailedNamesFilename = "amass_failed_names.ndjson"
+
+// The reasons recorded for the names that could not be resolved.
+const (
+	FailedServfail    = "servfail"
+	FailedTimeout     = "timeout"
+	FailedInterrupted = "interrupted"
+)
+
+// FailedName is the marker recorded for a name that could not be resolved during a run.
+type FailedName struct {
+	RunID  string    `json:"run_id"`
+	Name   string    `json:"name"`
+	Domain string    `json:"domain"`
+	Reason string    `json:"reason"`
+	Seen   time.Time `json:"seen"`
+}
+
+// FailedNamesFilepath returns the path of the file containing the failed name markers.
+func FailedNamesFilepath(cfg *config.Config) string {
+	return filepath.Join(config.OutputDirectory(cfg.Dir), FailedNamesFilename)
+}
+
+// LoadFailedNames returns the names that could not be resolved during the run, each listed once.
+func LoadFailedNames(cfg *config.Config, runID string) ([]*FailedName, error) {
+	f, err := os.Open(FailedNamesFilepath(cfg))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []*FailedName
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var fn FailedName
+		if err := json.Unmarshal(scanner.Bytes(), &fn); err != nil || fn.RunID != runID {
+			continue
+		}
+		if _, found := seen[fn.Name]; found {
+			continue
+		}
+		seen[fn.Name] = struct{}{}
+		names = append(names, &fn)
+	}
+	return names, scanner.Err()
+}
+
+type failedLog struct {
+	sync.Mutex
+	runID string
+	file  *os.File
+}
+
+func newFailedLog(path, runID string) (*failedLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the failed names file: %v", err)
+	}
+	return &failedLog{
+		runID: runID,
+		file:  f,
+	}, nil
+}
+
+// Write records the marker for a name that could not be resolved.
+func (l *failedLog) Write(name, domain, reason string) {
+	if l == nil {
+		return
+	}
+
+	data, err := json.Marshal(&FailedName{
+		RunID:  l.runID,
+		Name:   name,
+		Domain: domain,
+		Reason: reason,
+		Seen:   time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	_, _ = l.file.Write(append(data, '\n'))
+}
+
+func (l *failedLog) Close() {
+	if l == nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	_ = l.file.Sync()
+	_ = l.file.Close()
+}
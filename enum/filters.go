@@ -0,0 +1,98 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// Alphabetic runs shorter than this are not checked for pronounceability (e.g. www, smtp)
+	minPronounceableRun = 5
+	maxConsonantRun     = 4
+)
+
+// nameFilter drops machine-generated names based on the name_filters settings.
+type nameFilter struct {
+	settings *nameFilterSettings
+}
+
+func newNameFilter(settings *nameFilterSettings) *nameFilter {
+	if settings == nil || (settings.MaxLabelLen == 0 &&
+		settings.MinEntropy == 0 && !settings.RequirePronounceable) {
+		return nil
+	}
+	return &nameFilter{settings: settings}
+}
+
+// Accept returns false when a label of the name, below the root domain, appears to be machine-generated.
+func (f *nameFilter) Accept(name, domain string) bool {
+	if f == nil {
+		return true
+	}
+
+	sub := strings.TrimSuffix(name, domain)
+	for _, label := range strings.Split(strings.TrimSuffix(sub, "."), ".") {
+		if label == "" {
+			continue
+		}
+		if f.settings.MaxLabelLen > 0 && len(label) > f.settings.MaxLabelLen {
+			return false
+		}
+		if f.settings.MinEntropy > 0 && labelEntropy(label) >= f.settings.MinEntropy {
+			return false
+		}
+		if f.settings.RequirePronounceable && !pronounceable(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// labelEntropy returns the Shannon entropy of the label in bits per character.
+func labelEntropy(label string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range label {
+		counts[c]++
+	}
+
+	var entropy float64
+	total := float64(len(label))
+	for _, n := range counts {
+		p := float64(n) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// pronounceable checks each alphabetic run of the label for vowels and long consonant clusters.
+func pronounceable(label string) bool {
+	runs := strings.FieldsFunc(label, func(c rune) bool {
+		return c < 'a' || c > 'z'
+	})
+
+	for _, run := range runs {
+		if len(run) < minPronounceableRun {
+			continue
+		}
+
+		var vowels, consonants int
+		for _, c := range run {
+			if strings.ContainsRune("aeiouy", c) {
+				vowels++
+				consonants = 0
+				continue
+			}
+			if consonants++; consonants > maxConsonantRun {
+				return false
+			}
+		}
+		if vowels == 0 {
+			return false
+		}
+	}
+	return true
+}
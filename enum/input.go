@@ -88,7 +88,7 @@ func (r *enumSource) newName(req *requests.DNSRequest) {
 	// Clean up the newly discovered name and domain
 	requests.SanitizeDNSRequest(req)
 
-	if r.enum.Config.Blacklisted(req.Name) {
+	if r.enum.blacklisted(req.Name) || !r.enum.filter.Accept(req.Name, req.Domain) {
 		r.releaseOutput(1)
 		return
 	}
@@ -201,8 +201,14 @@ func (r *enumSource) monitorDataSrcOutput(srv service.Service) {
 			switch req := in.(type) {
 			case *requests.DNSRequest:
 				r.newName(req)
+				r.enum.sources.Add(req.Name, srv.String())
+				if srv.String() == bruteForcingSource {
+					r.enum.wordlists.Add(req.Name)
+				}
+				r.enum.perf.SourceResult(srv.String())
 			case *requests.AddrRequest:
 				r.newAddr(req)
+				r.enum.perf.SourceResult(srv.String())
 			}
 		}
 	}
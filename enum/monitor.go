@@ -59,6 +59,7 @@ func (e *Enumeration) checkForMissedWildcards(addr string) {
 	}
 
 	subsToAssets := make(map[string][]string)
+	idsToNames := make(map[string]string)
 	for _, rel := range in {
 		n, err := e.graph.DB.FindById(rel.FromAsset.ID, e.Config.CollectionStartTime.UTC())
 		if err != nil {
@@ -67,6 +68,7 @@ func (e *Enumeration) checkForMissedWildcards(addr string) {
 			parts := strings.Split(fqdn.Name, ".")
 			sub := strings.Join(parts[1:], ".")
 			subsToAssets[sub] = append(subsToAssets[sub], rel.FromAsset.ID)
+			idsToNames[rel.FromAsset.ID] = fqdn.Name
 		}
 	}
 
@@ -76,8 +78,12 @@ func (e *Enumeration) checkForMissedWildcards(addr string) {
 		}
 
 		e.Config.BlacklistSubdomain(sub)
+		graphWriteLock.Lock()
 		for _, id := range assets {
-			_ = e.graph.DB.DeleteAsset(id)
+			if err := e.graph.DB.DeleteAsset(id); err == nil {
+				e.dropped.Write(idsToNames[id], sub, []string{addr})
+			}
 		}
+		graphWriteLock.Unlock()
 	}
 }
@@ -125,7 +125,7 @@ func (r *subdomainTask) checkForSubdomains(ctx context.Context, req *requests.DN
 }
 
 func (r *subdomainTask) subWithinWildcard(ctx context.Context, name, domain string) bool {
-	for _, t := range FwdQueryTypes {
+	for _, t := range r.enum.fwdTypes {
 		select {
 		case <-ctx.Done():
 			return false
@@ -133,7 +133,7 @@ func (r *subdomainTask) subWithinWildcard(ctx context.Context, name, domain stri
 		}
 
 		if resp, err := r.enum.fwdQuery(ctx, "a."+name, t); err == nil &&
-			len(resp.Answer) > 0 && r.enum.Sys.TrustedResolvers().WildcardDetected(ctx, resp, domain) {
+			len(resp.Answer) > 0 && r.enum.wildcards.WildcardDetected(ctx, resp, domain) {
 			return true
 		}
 	}
@@ -213,7 +213,9 @@ func (r *subdomainTask) linkNodesToApexes() {
 			}
 
 			if apex != nil {
+				graphWriteLock.Lock()
 				_, _ = r.enum.graph.DB.Create(apex, "node", n)
+				graphWriteLock.Unlock()
 			}
 		}
 	}
@@ -0,0 +1,354 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// The number of unlikely names queried for each subdomain when the probes option is not provided.
+const defaultWildcardProbes int = 3
+
+// wildcardSettings contains the values provided in the wildcard section of the configuration options.
+// Probes is zero when the detection is left to the trusted resolver pool.
+type wildcardSettings struct {
+	LogDropped string
+	Probes     int
+	Threshold  int
+	CacheTTL   time.Duration
+}
+
+func loadWildcardSettings(cfg *config.Config) (*wildcardSettings, error) {
+	settings := new(wildcardSettings)
+
+	wildcardRaw, ok := cfg.Options["wildcard"]
+	if !ok {
+		return settings, nil
+	}
+
+	wildcard, ok := wildcardRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("wildcard is not a map[string]interface{}")
+	}
+
+	if logRaw, ok := wildcard["log_dropped"]; ok {
+		path, ok := logRaw.(string)
+		if !ok {
+			return nil, fmt.Errorf("wildcard log_dropped is not a string")
+		}
+		settings.LogDropped = path
+	}
+	if raw, ok := wildcard["probes"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 1 {
+			return nil, fmt.Errorf("wildcard probes is not a positive number")
+		}
+		settings.Probes = int(num)
+	}
+	if raw, ok := wildcard["threshold"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 1 {
+			return nil, fmt.Errorf("wildcard threshold is not a positive number")
+		}
+		settings.Threshold = int(num)
+	}
+	if raw, ok := wildcard["cache_ttl"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num <= 0 {
+			return nil, fmt.Errorf("wildcard cache_ttl is not a positive number of seconds")
+		}
+		settings.CacheTTL = time.Duration(num * float64(time.Second))
+	}
+	// Any of the detection options replaces the detection of the trusted resolver pool
+	if settings.Probes == 0 && (settings.Threshold > 0 || settings.CacheTTL > 0) {
+		settings.Probes = defaultWildcardProbes
+	}
+	if settings.Probes > 0 && settings.Threshold == 0 {
+		settings.Threshold = 1
+	}
+	if settings.Threshold > settings.Probes {
+		return nil, fmt.Errorf("wildcard threshold cannot be larger than the number of probes")
+	}
+	return settings, nil
+}
+
+// nameFilterSettings contains the values provided in the name_filters section of the configuration options.
+type nameFilterSettings struct {
+	MaxLabelLen          int
+	MinEntropy           float64
+	RequirePronounceable bool
+}
+
+func loadNameFilterSettings(cfg *config.Config) (*nameFilterSettings, error) {
+	settings := new(nameFilterSettings)
+
+	filtersRaw, ok := cfg.Options["name_filters"]
+	if !ok {
+		return settings, nil
+	}
+
+	filters, ok := filtersRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("name_filters is not a map[string]interface{}")
+	}
+
+	if raw, ok := filters["max_label_len"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 0 {
+			return nil, fmt.Errorf("name_filters max_label_len is not a positive number")
+		}
+		settings.MaxLabelLen = int(num)
+	}
+	if raw, ok := filters["min_entropy"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 0 {
+			return nil, fmt.Errorf("name_filters min_entropy is not a positive number")
+		}
+		settings.MinEntropy = num
+	}
+	if raw, ok := filters["require_pronounceable"]; ok {
+		pronounceable, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("name_filters require_pronounceable is not a bool")
+		}
+		settings.RequirePronounceable = pronounceable
+	}
+	return settings, nil
+}
+
+// confirmSettings contains the confirm_resolvers and confirm_threshold values provided in the configuration options.
+type confirmSettings struct {
+	Resolvers []string
+	Threshold int
+}
+
+func loadConfirmSettings(cfg *config.Config) (*confirmSettings, error) {
+	settings := new(confirmSettings)
+
+	if raw, ok := cfg.Options["confirm_resolvers"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("confirm_resolvers is not a []interface{}")
+		}
+
+		for _, r := range list {
+			addr, ok := r.(string)
+			if !ok || addr == "" {
+				return nil, fmt.Errorf("confirm_resolvers contains an element that is not a string")
+			}
+			settings.Resolvers = append(settings.Resolvers, addr)
+		}
+	}
+	// By default, a majority of the confirm resolvers must provide an answer
+	settings.Threshold = (len(settings.Resolvers) / 2) + 1
+
+	if raw, ok := cfg.Options["confirm_threshold"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 1 {
+			return nil, fmt.Errorf("confirm_threshold is not a number greater than zero")
+		}
+		if int(num) > len(settings.Resolvers) {
+			return nil, fmt.Errorf("confirm_threshold is greater than the number of confirm_resolvers")
+		}
+		settings.Threshold = int(num)
+	}
+	return settings, nil
+}
+
+// The values accepted by the address_family setting in the dns section of the configuration options.
+const (
+	AddressFamilyIPv4    = "ipv4"
+	AddressFamilyIPv6    = "ipv6"
+	AddressFamilyBoth    = "both"
+	AddressFamilyPrefer6 = "prefer6"
+)
+
+// AddressFamily returns the address_family provided in the dns section of the configuration options,
+// which controls the address record types queried and the addresses kept. The default is both.
+func AddressFamily(cfg *config.Config) (string, error) {
+	settings, err := dnsOptions(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	raw, ok := settings["address_family"]
+	if !ok {
+		return AddressFamilyBoth, nil
+	}
+
+	family, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("dns address_family is not a string")
+	}
+
+	switch family {
+	case AddressFamilyIPv4, AddressFamilyIPv6, AddressFamilyBoth, AddressFamilyPrefer6:
+		return family, nil
+	}
+	return "", fmt.Errorf("dns address_family must be one of ipv4, ipv6, both or prefer6")
+}
+
+func loadRecordAnswers(cfg *config.Config) (bool, error) {
+	settings, err := dnsOptions(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok := settings["record_answers"]
+	if !ok {
+		return false, nil
+	}
+
+	record, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("dns record_answers is not a bool")
+	}
+	return record, nil
+}
+
+// httpProbeSettings contains the values provided in the http_probe section of the configuration options.
+type httpProbeSettings struct {
+	Enabled         bool
+	Ports           []int
+	FollowRedirects bool
+}
+
+func loadHTTPProbeSettings(cfg *config.Config) (*httpProbeSettings, error) {
+	settings := &httpProbeSettings{Ports: []int{80, 443}}
+
+	probeRaw, ok := cfg.Options["http_probe"]
+	if !ok {
+		return settings, nil
+	}
+
+	probe, ok := probeRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("http_probe is not a map[string]interface{}")
+	}
+
+	if raw, ok := probe["enabled"]; ok {
+		enabled, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("http_probe enabled is not a bool")
+		}
+		settings.Enabled = enabled
+	}
+	if raw, ok := probe["ports"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("http_probe ports is not a list of port numbers")
+		}
+
+		settings.Ports = nil
+		for _, p := range list {
+			num, ok := optionNumber(p)
+			if !ok || num < 1 || num > 65535 {
+				return nil, fmt.Errorf("http_probe ports contains an invalid port number: %v", p)
+			}
+			settings.Ports = append(settings.Ports, int(num))
+		}
+	}
+	if raw, ok := probe["follow_redirects"]; ok {
+		follow, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("http_probe follow_redirects is not a bool")
+		}
+		settings.FollowRedirects = follow
+	}
+	return settings, nil
+}
+
+// breakerSettings contains the timeout_threshold and timeout_backoff values provided in the dns section
+// of the configuration options.
+type breakerSettings struct {
+	Threshold float64
+	Backoff   time.Duration
+}
+
+func loadBreakerSettings(cfg *config.Config) (*breakerSettings, error) {
+	settings := &breakerSettings{
+		Threshold: defaultTimeoutThreshold,
+		Backoff:   defaultTimeoutBackoff,
+	}
+
+	dnsopts, err := dnsOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw, ok := dnsopts["timeout_threshold"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num < 0 || num > 1 {
+			return nil, fmt.Errorf("dns timeout_threshold is not a number between 0 and 1")
+		}
+		settings.Threshold = num
+	}
+	if raw, ok := dnsopts["timeout_backoff"]; ok {
+		num, ok := optionNumber(raw)
+		if !ok || num <= 0 {
+			return nil, fmt.Errorf("dns timeout_backoff is not a positive number of seconds")
+		}
+		settings.Backoff = time.Duration(num * float64(time.Second))
+	}
+	return settings, nil
+}
+
+// dnsOptions returns the dns section of the configuration options, or nil when it was not provided.
+func dnsOptions(cfg *config.Config) (map[string]interface{}, error) {
+	raw, ok := cfg.Options["dns"]
+	if !ok {
+		return nil, nil
+	}
+
+	settings, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("dns is not a map[string]interface{}")
+	}
+	return settings, nil
+}
+
+func loadMaxSourcesPerName(cfg *config.Config) (int, error) {
+	raw, ok := cfg.Options["max_sources_per_name"]
+	if !ok {
+		return 0, nil
+	}
+
+	num, ok := optionNumber(raw)
+	if !ok || num < 0 {
+		return 0, fmt.Errorf("max_sources_per_name is not a positive number")
+	}
+	return int(num), nil
+}
+
+func loadDeterministicSources(cfg *config.Config) (bool, error) {
+	raw, ok := cfg.Options["deterministic_sources"]
+	if !ok {
+		return false, nil
+	}
+
+	deterministic, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("deterministic_sources is not a bool")
+	}
+	return deterministic, nil
+}
+
+// optionNumber converts the numeric types produced by the YAML decoder into a float64.
+func optionNumber(raw interface{}) (float64, bool) {
+	switch v := raw.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,157 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// The names of the timed stages provided in the performance report.
+const (
+	PerfDNSUntrusted = "dns_untrusted"
+	PerfDNSTrusted   = "dns_trusted"
+	PerfConfirm      = "confirm"
+	PerfDBWrite      = "db_write"
+)
+
+// PerfReport is the breakdown of where the time of an enumeration was spent.
+type PerfReport struct {
+	Elapsed float64       `json:"elapsed_seconds"`
+	Stages  []*PerfStage  `json:"stages"`
+	Sources []*PerfSource `json:"sources"`
+}
+
+// PerfStage contains the time spent on the items processed by a stage of the enumeration.
+// The stages run concurrently, so the total can exceed the elapsed time of the enumeration.
+type PerfStage struct {
+	Name    string  `json:"name"`
+	Count   int     `json:"count"`
+	Total   float64 `json:"total_seconds"`
+	Average float64 `json:"average_seconds"`
+}
+
+// PerfSource contains the activity of a data source. The elapsed time is measured from the
+// first request sent to the data source until the last result it returned.
+type PerfSource struct {
+	Name     string  `json:"name"`
+	Requests int     `json:"requests"`
+	Results  int     `json:"results"`
+	Elapsed  float64 `json:"elapsed_seconds"`
+}
+
+type perfStat struct {
+	count int
+	total time.Duration
+}
+
+type sourceStat struct {
+	requests int
+	results  int
+	first    time.Time
+	last     time.Time
+}
+
+// perfTimers accumulates the time spent in each part of the enumeration.
+type perfTimers struct {
+	sync.Mutex
+	start   time.Time
+	stages  map[string]*perfStat
+	sources map[string]*sourceStat
+}
+
+func newPerfTimers() *perfTimers {
+	return &perfTimers{
+		start:   time.Now(),
+		stages:  make(map[string]*perfStat),
+		sources: make(map[string]*sourceStat),
+	}
+}
+
+// Add records the time spent by the stage on a single item.
+func (p *perfTimers) Add(stage string, d time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	s, found := p.stages[stage]
+	if !found {
+		s = new(perfStat)
+		p.stages[stage] = s
+	}
+	s.count++
+	s.total += d
+}
+
+// SourceRequest records a request sent to the named data source.
+func (p *perfTimers) SourceRequest(name string) {
+	p.Lock()
+	defer p.Unlock()
+
+	s := p.source(name)
+	s.requests++
+	if s.first.IsZero() {
+		s.first = time.Now()
+	}
+}
+
+// SourceResult records a result returned by the named data source.
+func (p *perfTimers) SourceResult(name string) {
+	p.Lock()
+	defer p.Unlock()
+
+	s := p.source(name)
+	s.results++
+	s.last = time.Now()
+}
+
+func (p *perfTimers) source(name string) *sourceStat {
+	s, found := p.sources[name]
+	if !found {
+		s = new(sourceStat)
+		p.sources[name] = s
+	}
+	return s
+}
+
+// Report returns the performance report for the time recorded so far.
+func (p *perfTimers) Report() *PerfReport {
+	p.Lock()
+	defer p.Unlock()
+
+	report := &PerfReport{Elapsed: time.Since(p.start).Seconds()}
+	for name, s := range p.stages {
+		stage := &PerfStage{
+			Name:  name,
+			Count: s.count,
+			Total: s.total.Seconds(),
+		}
+		if s.count > 0 {
+			stage.Average = stage.Total / float64(s.count)
+		}
+		report.Stages = append(report.Stages, stage)
+	}
+	sort.Slice(report.Stages, func(i, j int) bool { return report.Stages[i].Name < report.Stages[j].Name })
+
+	for name, s := range p.sources {
+		src := &PerfSource{
+			Name:     name,
+			Requests: s.requests,
+			Results:  s.results,
+		}
+		if !s.first.IsZero() && s.last.After(s.first) {
+			src.Elapsed = s.last.Sub(s.first).Seconds()
+		}
+		report.Sources = append(report.Sources, src)
+	}
+	// The slowest data sources are listed first
+	sort.Slice(report.Sources, func(i, j int) bool {
+		if report.Sources[i].Elapsed == report.Sources[j].Elapsed {
+			return report.Sources[i].Name < report.Sources[j].Name
+		}
+		return report.Sources[i].Elapsed > report.Sources[j].Elapsed
+	})
+	return report
+}
@@ -0,0 +1,47 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+
+	"github.com/owasp-amass/amass/v4/requests"
+)
+
+// PostProcessor receives each name resolved by the enumeration before it is stored in the graph database.
+// The requests returned are stored in place of the one received, so the processor can enrich the request,
+// drop it by returning none, or add related requests. The first request returned continues through the
+// pipeline, while the others are only stored.
+type PostProcessor func(ctx context.Context, req *requests.DNSRequest) []*requests.DNSRequest
+
+// RegisterPostProcessor adds the processor to those run, in the order of registration, on the resolved names.
+// The processors must be registered before the enumeration is started, and they are shared with its chunks.
+func (e *Enumeration) RegisterPostProcessor(fn PostProcessor) {
+	if fn != nil {
+		e.postProcs = append(e.postProcs, fn)
+	}
+}
+
+// postProcess returns the requests produced by the registered processors from the resolved name.
+func (e *Enumeration) postProcess(ctx context.Context, req *requests.DNSRequest) []*requests.DNSRequest {
+	reqs := []*requests.DNSRequest{req}
+
+	for _, fn := range e.postProcs {
+		var next []*requests.DNSRequest
+
+		for _, r := range reqs {
+			for _, out := range fn(ctx, r) {
+				if out != nil && out.Name != "" {
+					next = append(next, out)
+				}
+			}
+		}
+
+		if reqs = next; len(reqs) == 0 {
+			break
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,150 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/config/config"
+)
+
+// HTTPProbeFilename is the file in the output directory where the HTTP probe results are recorded
+// when the http_probe section of the configuration options is enabled.
+const HTTPProbeFilename = "amass_http_probe.ndjson"
+
+// The maximum number of names probed at the same time.
+const maxConcurrentProbes = 10
+
+// HTTPProbeResult is the response received when probing a port of a resolved name.
+type HTTPProbeResult struct {
+	Name   string    `json:"name"`
+	URL    string    `json:"url"`
+	Status int       `json:"status"`
+	Server string    `json:"server,omitempty"`
+	Seen   time.Time `json:"seen"`
+}
+
+// HTTPProbeFilepath returns the path of the file containing the HTTP probe results.
+func HTTPProbeFilepath(cfg *config.Config) string {
+	return filepath.Join(config.OutputDirectory(cfg.Dir), HTTPProbeFilename)
+}
+
+// httpProber sends a lightweight HTTP(S) request to the ports of each resolved name.
+type httpProber struct {
+	sync.Mutex
+	ctx      context.Context
+	settings *httpProbeSettings
+	file     *os.File
+	probed   map[string]struct{}
+	slots    chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newHTTPProber(ctx context.Context, path string, settings *httpProbeSettings) (*httpProber, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the HTTP probe file: %v", err)
+	}
+
+	return &httpProber{
+		ctx:      ctx,
+		settings: settings,
+		file:     f,
+		probed:   make(map[string]struct{}),
+		slots:    make(chan struct{}, maxConcurrentProbes),
+	}, nil
+}
+
+// Probe requests the configured ports of the name once, in the background.
+func (p *httpProber) Probe(name string) {
+	if p == nil {
+		return
+	}
+
+	p.Lock()
+	if _, found := p.probed[name]; found {
+		p.Unlock()
+		return
+	}
+	p.probed[name] = struct{}{}
+	p.Unlock()
+
+	p.wg.Add(1)
+	go p.probe(name)
+}
+
+func (p *httpProber) probe(name string) {
+	defer p.wg.Done()
+
+	select {
+	case <-p.ctx.Done():
+		return
+	case p.slots <- struct{}{}:
+	}
+	defer func() { <-p.slots }()
+
+	for _, port := range p.settings.Ports {
+		u := probeURL(name, port)
+
+		resp, err := amasshttp.ProbeURL(p.ctx, u, p.settings.FollowRedirects)
+		if err != nil {
+			continue
+		}
+		p.write(&HTTPProbeResult{
+			Name:   name,
+			URL:    u,
+			Status: resp.StatusCode,
+			Server: resp.Header["Server"],
+			Seen:   time.Now().UTC(),
+		})
+	}
+}
+
+func (p *httpProber) write(result *HTTPProbeResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	_, _ = p.file.Write(append(data, '\n'))
+}
+
+// Close waits for the probes in progress and closes the file.
+func (p *httpProber) Close() {
+	if p == nil {
+		return
+	}
+
+	p.wg.Wait()
+	p.Lock()
+	defer p.Unlock()
+
+	_ = p.file.Sync()
+	_ = p.file.Close()
+}
+
+// probeURL returns the URL requested for the port, using HTTPS for the common TLS ports.
+func probeURL(name string, port int) string {
+	switch port {
+	case 80:
+		return "http://" + name
+	case 443:
+		return "https://" + name
+	case 4443, 8443:
+		return "https://" + name + ":" + strconv.Itoa(port)
+	}
+	return "http://" + name + ":" + strconv.Itoa(port)
+}
@@ -0,0 +1,122 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"sync"
+	"sync/atomic"
+
+	amassnet "github.com/owasp-amass/amass/v4/net"
+)
+
+// Progress contains the live counts of an enumeration, including its running chunks.
+type Progress struct {
+	// Names is the number of names resolved and stored so far
+	Names int64
+	// DNSQueries is the number of DNS queries issued by the process
+	DNSQueries int64
+	// ActiveSources is the number of data sources processing requests
+	ActiveSources int
+	// Queued is the number of names waiting in, or moving through, the pipelines
+	Queued int
+}
+
+// progressTracker is shared by an enumeration and its chunks.
+type progressTracker struct {
+	sync.Mutex
+	names   atomic.Int64
+	running map[*Enumeration]struct{}
+	stopped bool
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{running: make(map[*Enumeration]struct{})}
+}
+
+func (p *progressTracker) addName() {
+	p.names.Add(1)
+}
+
+// track adds the running enumeration to those providing the active data sources and queued names.
+func (p *progressTracker) track(e *Enumeration) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.running[e] = struct{}{}
+	if p.stopped {
+		e.cancel()
+	}
+}
+
+func (p *progressTracker) untrack(e *Enumeration) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.running, e)
+}
+
+func (p *progressTracker) report() *Progress {
+	p.Lock()
+	defer p.Unlock()
+
+	active := make(map[string]struct{})
+	prog := &Progress{
+		Names:      p.names.Load(),
+		DNSQueries: amassnet.DNSQueries(),
+	}
+	for e := range p.running {
+		for _, name := range e.activeSources() {
+			active[name] = struct{}{}
+		}
+		prog.Queued += e.nameSrc.queue.Len() + e.nameSrc.pipeline.DataItemCount()
+	}
+	prog.ActiveSources = len(active)
+	return prog
+}
+
+// stop cancels the running enumerations, and the enumerations tracked afterward.
+func (p *progressTracker) stop() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.stopped = true
+	for e := range p.running {
+		e.cancel()
+	}
+}
+
+func (p *progressTracker) isStopped() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.stopped
+}
+
+// Progress returns the live counts of the enumeration. It can be called while the enumeration is running.
+func (e *Enumeration) Progress() *Progress {
+	return e.progress.report()
+}
+
+// setActiveSources records the names of the data sources with requests in flight or waiting.
+func (e *Enumeration) setActiveSources(pending map[string]bool) {
+	var names []string
+
+	for name, b := range pending {
+		if b {
+			names = append(names, name)
+		}
+	}
+
+	e.plock.Lock()
+	e.active = names
+	e.plock.Unlock()
+}
+
+func (e *Enumeration) activeSources() []string {
+	e.plock.Lock()
+	defer e.plock.Unlock()
+
+	return e.active
+}
@@ -0,0 +1,106 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/amass/v4/requests"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/resolve"
+)
+
+// DNSRecordsFilename is the file in the output directory where the raw DNS answers are recorded
+// when the record_answers setting is enabled in the dns section of the configuration options.
+const DNSRecordsFilename = "amass_dns_records.ndjson"
+
+// DNSRecord is a raw DNS answer stored for a discovered name.
+type DNSRecord struct {
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+	TTL  int       `json:"ttl"`
+	Data string    `json:"data"`
+	Seen time.Time `json:"seen"`
+}
+
+// DNSRecordsFilepath returns the path of the file containing the raw DNS answers.
+func DNSRecordsFilepath(cfg *config.Config) string {
+	return filepath.Join(config.OutputDirectory(cfg.Dir), DNSRecordsFilename)
+}
+
+type recordLog struct {
+	sync.Mutex
+	file *os.File
+}
+
+func newRecordLog(path string) (*recordLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the DNS records file: %v", err)
+	}
+	return &recordLog{file: f}, nil
+}
+
+// Write records the raw DNS answers that were stored for the name.
+func (l *recordLog) Write(answers []requests.DNSAnswer) {
+	if l == nil || len(answers) == 0 {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now().UTC()
+	for _, a := range answers {
+		rtype := dns.TypeToString[uint16(a.Type)]
+		if rtype == "" {
+			rtype = fmt.Sprintf("TYPE%d", a.Type)
+		}
+
+		if data, err := json.Marshal(&DNSRecord{
+			Name: a.Name,
+			Type: rtype,
+			TTL:  a.TTL,
+			Data: a.Data,
+			Seen: now,
+		}); err == nil {
+			_, _ = l.file.Write(append(data, '\n'))
+		}
+	}
+}
+
+func (l *recordLog) Close() {
+	if l == nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	_ = l.file.Sync()
+	_ = l.file.Close()
+}
+
+// answerTTL returns the TTL of the resource record in the DNS message that provided the answer.
+func answerTTL(resp *dns.Msg, a *resolve.ExtractedAnswer) int {
+	if resp == nil {
+		return 0
+	}
+
+	for _, rr := range resp.Answer {
+		hdr := rr.Header()
+		if hdr.Rrtype == a.Type && strings.ToLower(resolve.RemoveLastDot(hdr.Name)) == a.Name {
+			return int(hdr.Ttl)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
+	"github.com/owasp-amass/config/config"
+)
+
+// RegistrarsFilename is the file in the output directory where the registrar of each root domain name
+// is recorded during the enumeration. The asset model has no asset or relation for the registration data
+// of a domain name, so it cannot be stored in the graph database with the NS records.
+const RegistrarsFilename = "amass_registrars.ndjson"
+
+// RegistrarRecord is the registrar obtained from RDAP for a root domain name.
+type RegistrarRecord struct {
+	Domain    string    `json:"domain"`
+	Registrar string    `json:"registrar"`
+	Seen      time.Time `json:"seen"`
+}
+
+// RegistrarsFilepath returns the path of the file containing the registrar records.
+func RegistrarsFilepath(cfg *config.Config) string {
+	return filepath.Join(config.OutputDirectory(cfg.Dir), RegistrarsFilename)
+}
+
+// LoadRegistrars returns the latest registrar recorded for each root domain name.
+func LoadRegistrars(cfg *config.Config) (map[string]*RegistrarRecord, error) {
+	records := make(map[string]*RegistrarRecord)
+
+	f, err := os.Open(RegistrarsFilepath(cfg))
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec RegistrarRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.Domain == "" {
+			continue
+		}
+		if prev, found := records[rec.Domain]; !found || rec.Seen.After(prev.Seen) {
+			records[rec.Domain] = &rec
+		}
+	}
+	return records, scanner.Err()
+}
+
+type registrarLog struct {
+	sync.Mutex
+	file *os.File
+	wg   sync.WaitGroup
+}
+
+func newRegistrarLog(path string) (*registrarLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the registrars file: %v", err)
+	}
+	return &registrarLog{file: f}, nil
+}
+
+// Lookup requests the registrar of each root domain name from RDAP in the background, and records those found.
+func (l *registrarLog) Lookup(ctx context.Context, domains []string) {
+	if l == nil {
+		return
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		for _, domain := range domains {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			// The bootstrap service redirects to the RDAP server of the registry, so each lookup
+			// sends a request beyond the one counted by the HTTP client
+			if err := amassnet.WaitForEgress(ctx); err != nil {
+				return
+			}
+			// RDAP only provides the registration data of the registrable domain
+			apex, err := amassdns.EffectiveTLDPlusOne(domain)
+			if err != nil {
+				apex = domain
+			}
+
+			if registrar, err := amasshttp.RDAPRegistrar(ctx, apex); err == nil {
+				l.write(strings.ToLower(domain), registrar)
+			}
+		}
+	}()
+}
+
+func (l *registrarLog) write(domain, registrar string) {
+	data, err := json.Marshal(&RegistrarRecord{
+		Domain:    domain,
+		Registrar: registrar,
+		Seen:      time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	_, _ = l.file.Write(append(data, '\n'))
+}
+
+// Close waits for the lookups in progress and closes the file.
+func (l *registrarLog) Close() {
+	if l == nil {
+		return
+	}
+
+	l.wg.Wait()
+	l.Lock()
+	defer l.Unlock()
+
+	_ = l.file.Sync()
+	_ = l.file.Close()
+}
@@ -0,0 +1,145 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/owasp-amass/config/config"
+	"gopkg.in/yaml.v3"
+)
+
+// The reputation weight used for data sources not assigned one in the datasources file.
+const defaultReputation = 0.5
+
+// sourceTracker records the data sources that reported each name and scores the names by the
+// reputation weights of those data sources.
+type sourceTracker struct {
+	sync.Mutex
+	weights map[string]float64
+	// The data source names reported for each name, keyed by the lowercase data source name
+	names map[string]map[string]string
+}
+
+func newSourceTracker() *sourceTracker {
+	return &sourceTracker{
+		weights: make(map[string]float64),
+		names:   make(map[string]map[string]string),
+	}
+}
+
+func (st *sourceTracker) SetWeights(weights map[string]float64) {
+	st.Lock()
+	defer st.Unlock()
+
+	st.weights = weights
+}
+
+// Add records that the data source reported the name.
+func (st *sourceTracker) Add(name, source string) {
+	st.Lock()
+	defer st.Unlock()
+
+	srcs, found := st.names[name]
+	if !found {
+		srcs = make(map[string]string)
+		st.names[name] = srcs
+	}
+	srcs[strings.ToLower(source)] = source
+}
+
+// Count returns the number of data sources that reported the name.
+func (st *sourceTracker) Count(name string) int {
+	st.Lock()
+	defer st.Unlock()
+
+	return len(st.names[name])
+}
+
+// Sources returns the sorted names of the data sources that reported the name.
+func (st *sourceTracker) Sources(name string) []string {
+	st.Lock()
+	defer st.Unlock()
+
+	var srcs []string
+	for _, src := range st.names[name] {
+		srcs = append(srcs, src)
+	}
+	sort.Strings(srcs)
+	return srcs
+}
+
+// Score returns the probability that at least one of the data sources reporting the name is correct,
+// treating each reputation weight as an independent probability. False is returned when no data source
+// reported the name.
+func (st *sourceTracker) Score(name string) (float64, bool) {
+	st.Lock()
+	defer st.Unlock()
+
+	srcs, found := st.names[name]
+	if !found || len(srcs) == 0 {
+		return 0, false
+	}
+
+	miss := 1.0
+	for src := range srcs {
+		weight, ok := st.weights[src]
+		if !ok {
+			weight = defaultReputation
+		}
+		miss *= 1 - weight
+	}
+	return 1 - miss, true
+}
+
+// loadSourceReputation reads the reputation weights assigned to the data sources in the datasources file.
+func loadSourceReputation(cfg *config.Config) (map[string]float64, error) {
+	weights := make(map[string]float64)
+
+	raw, ok := cfg.Options["datasources"]
+	if !ok {
+		return weights, nil
+	}
+
+	path, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("datasources option is not a string")
+	}
+
+	abs, err := cfg.AbsPathFromConfigDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("error reading datasources file: %v", err)
+	}
+
+	var file struct {
+		Datasources []struct {
+			Name       string   `yaml:"name"`
+			Reputation *float64 `yaml:"reputation"`
+		} `yaml:"datasources"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error unmarshalling datasources YAML: %v", err)
+	}
+
+	for _, src := range file.Datasources {
+		if src.Reputation == nil {
+			continue
+		}
+		if r := *src.Reputation; r < 0 || r > 1 {
+			return nil, fmt.Errorf("the %s reputation must be between 0 and 1", src.Name)
+		}
+		weights[strings.ToLower(src.Name)] = *src.Reputation
+	}
+	return weights, nil
+}
@@ -11,6 +11,7 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/caffix/pipeline"
@@ -21,9 +22,11 @@ import (
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/resolve"
 	bf "github.com/tylertreat/BoomFilters"
-	"golang.org/x/net/publicsuffix"
 )
 
+// graphWriteLock serializes the writes made by concurrent enumerations that share a graph database.
+var graphWriteLock sync.Mutex
+
 // dataManager is the stage that stores all data processed by the pipeline.
 type dataManager struct {
 	enum        *Enumeration
@@ -68,17 +71,34 @@ func (dm *dataManager) Process(ctx context.Context, data pipeline.Data, tp pipel
 			return nil, nil
 		}
 
-		id = v.Name
-		if err := dm.dnsRequest(ctx, v, tp); err != nil {
-			dm.enum.Config.Log.Print(err.Error())
+		reqs := dm.enum.postProcess(ctx, v)
+		if len(reqs) == 0 {
+			return nil, nil
 		}
+
+		id = reqs[0].Name
+		data = reqs[0]
+		graphWriteLock.Lock()
+		start := time.Now()
+		for _, req := range reqs {
+			if err := dm.dnsRequest(ctx, req, tp); err != nil {
+				dm.enum.Config.Log.Print(err.Error())
+			}
+		}
+		dm.enum.perf.Add(PerfDBWrite, time.Since(start))
+		graphWriteLock.Unlock()
 	case *requests.AddrRequest:
 		if v == nil {
 			return nil, nil
 		}
 
 		id = v.Address
-		if err := dm.addrRequest(ctx, v, tp); err != nil {
+		graphWriteLock.Lock()
+		start := time.Now()
+		err := dm.addrRequest(ctx, v, tp)
+		dm.enum.perf.Add(PerfDBWrite, time.Since(start))
+		graphWriteLock.Unlock()
+		if err != nil {
 			dm.enum.Config.Log.Print(err.Error())
 		}
 	}
@@ -86,13 +106,18 @@ func (dm *dataManager) Process(ctx context.Context, data pipeline.Data, tp pipel
 	if id != "" && dm.filter.TestAndAdd([]byte(id)) {
 		return nil, nil
 	}
+	if _, ok := data.(*requests.DNSRequest); ok {
+		dm.enum.progress.addName()
+	}
 	return data, nil
 }
 
 func (dm *dataManager) dnsRequest(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) error {
-	if dm.enum.Config.Blacklisted(req.Name) {
+	if dm.enum.blacklisted(req.Name) {
 		return nil
 	}
+	// Preserve the answers, as they were received, for the DNS records file
+	dm.enum.records.Write(req.Records)
 	// Check for CNAME records first
 	for i, r := range req.Records {
 		req.Records[i].Name = strings.Trim(strings.ToLower(r.Name), ".")
@@ -115,9 +140,15 @@ func (dm *dataManager) dnsRequest(ctx context.Context, req *requests.DNSRequest,
 		var e error
 		switch uint16(r.Type) {
 		case dns.TypeA:
-			e = dm.insertA(ctx, req, i, tp)
+			if dm.enum.family != AddressFamilyIPv6 {
+				e = dm.insertA(ctx, req, i, tp)
+				dm.enum.prober.Probe(req.Name)
+			}
 		case dns.TypeAAAA:
-			e = dm.insertAAAA(ctx, req, i, tp)
+			if dm.enum.family != AddressFamilyIPv4 {
+				e = dm.insertAAAA(ctx, req, i, tp)
+				dm.enum.prober.Probe(req.Name)
+			}
 		case dns.TypePTR:
 			e = dm.insertPTR(ctx, req, i, tp)
 		case dns.TypeSRV:
@@ -146,7 +177,7 @@ func (dm *dataManager) insertCNAME(ctx context.Context, req *requests.DNSRequest
 		return errors.New("failed to extract a FQDN from the DNS answer data")
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := amassdns.EffectiveTLDPlusOne(target)
 	if err != nil || domain == "" {
 		return errors.New("failed to extract a domain name from the FQDN")
 	}
@@ -240,7 +271,7 @@ func (dm *dataManager) insertNS(ctx context.Context, req *requests.DNSRequest, r
 		return errors.New("failed to extract NS info from the DNS answer data")
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := amassdns.EffectiveTLDPlusOne(target)
 	if err != nil || domain == "" {
 		return errors.New("failed to extract a domain name from the FQDN")
 	}
@@ -262,7 +293,7 @@ func (dm *dataManager) insertMX(ctx context.Context, req *requests.DNSRequest, r
 		return errors.New("failed to extract a FQDN from the DNS answer data")
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := amassdns.EffectiveTLDPlusOne(target)
 	if err != nil || domain == "" {
 		return errors.New("failed to extract a domain name from the FQDN")
 	}
@@ -329,6 +360,12 @@ func (dm *dataManager) addrRequest(ctx context.Context, req *requests.AddrReques
 	if req == nil || !req.InScope {
 		return nil
 	}
+	dm.enum.certs.Pull(req.Address)
+	// Only the raw address is stored when the infrastructure lookups are disabled
+	if !dm.enum.infra {
+		_, err := dm.enum.graph.UpsertAddress(ctx, req.Address)
+		return err
+	}
 	if yes, prefix := amassnet.IsReservedAddress(req.Address); yes {
 		var err error
 		if e := dm.enum.graph.UpsertInfrastructure(ctx, 0, amassnet.ReservedCIDRDescription, req.Address, prefix); e != nil {
@@ -373,7 +410,7 @@ func (dm *dataManager) nextInfraInfo() {
 	ctx := context.Background()
 	req := e.(*requests.AddrRequest)
 	if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
-		_ = dm.enum.graph.UpsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix)
+		_ = dm.upsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix)
 		return
 	}
 
@@ -388,7 +425,7 @@ loop:
 
 		time.Sleep(2 * time.Second)
 		if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
-			_ = dm.enum.graph.UpsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix)
+			_ = dm.upsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix)
 			return
 		}
 	}
@@ -396,7 +433,7 @@ loop:
 	asn := 0
 	desc := "Unknown"
 	prefix := fakePrefix(req.Address)
-	_ = dm.enum.graph.UpsertInfrastructure(ctx, asn, desc, req.Address, prefix)
+	_ = dm.upsertInfrastructure(ctx, asn, desc, req.Address, prefix)
 
 	first, cidr, _ := net.ParseCIDR(prefix)
 	dm.enum.Sys.Cache().Update(&requests.ASNRequest{
@@ -407,6 +444,13 @@ loop:
 	})
 }
 
+func (dm *dataManager) upsertInfrastructure(ctx context.Context, asn int, desc, addr, prefix string) error {
+	graphWriteLock.Lock()
+	defer graphWriteLock.Unlock()
+
+	return dm.enum.graph.UpsertInfrastructure(ctx, asn, desc, addr, prefix)
+}
+
 func fakePrefix(addr string) string {
 	bits := 24
 	total := 32
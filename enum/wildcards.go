@@ -0,0 +1,219 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/resolve"
+)
+
+// The number of attempts made by the trusted resolvers for each query of a wildcard probe.
+const wildcardQueryAttempts = 5
+
+// wildcardDetector decides if a DNS response could be the answer of a wildcard within the domain.
+// The trusted resolver pool provides the default detection.
+type wildcardDetector interface {
+	WildcardDetected(ctx context.Context, resp *dns.Msg, domain string) bool
+}
+
+// probeDetector detects DNS wildcards by querying unlikely names with the trusted resolvers, using the
+// number of probes and matching answers provided in the wildcard section of the configuration options.
+type probeDetector struct {
+	sync.Mutex
+	enum      *Enumeration
+	probes    int
+	threshold int
+	ttl       time.Duration
+	subs      map[string]*probeResult
+}
+
+// probeResult holds the outcome of the probes for a subdomain. The answers map is replaced,
+// never modified, when the subdomain is probed again.
+type probeResult struct {
+	sync.Mutex
+	checked  time.Time
+	detected bool
+	answers  map[string]struct{}
+}
+
+func newProbeDetector(e *Enumeration, settings *wildcardSettings) *probeDetector {
+	return &probeDetector{
+		enum:      e,
+		probes:    settings.Probes,
+		threshold: settings.Threshold,
+		ttl:       settings.CacheTTL,
+		subs:      make(map[string]*probeResult),
+	}
+}
+
+// WildcardDetected returns true when the provided DNS response could be a wildcard match.
+func (p *probeDetector) WildcardDetected(ctx context.Context, resp *dns.Msg, domain string) bool {
+	if len(resp.Question) == 0 {
+		return false
+	}
+
+	name := strings.ToLower(resolve.RemoveLastDot(resp.Question[0].Name))
+	domain = strings.ToLower(resolve.RemoveLastDot(domain))
+	if labels := strings.Split(name, "."); len(labels) > len(strings.Split(domain, ".")) {
+		name = strings.Join(labels[1:], ".")
+	}
+
+	var found bool
+	// Check for a DNS wildcard at each label starting with the registered domain
+	resolve.RegisteredToFQDN(domain, name, func(sub string) bool {
+		if detected, answers := p.lookup(ctx, sub); detected && respMatchesAnswers(resp, answers) {
+			found = true
+		}
+		return found
+	})
+	return found
+}
+
+// lookup returns the cached outcome of the probes for the subdomain, and probes it again
+// when the outcome is missing or older than the TTL.
+func (p *probeDetector) lookup(ctx context.Context, sub string) (bool, map[string]struct{}) {
+	p.Lock()
+	res, found := p.subs[sub]
+	if !found {
+		res = new(probeResult)
+		p.subs[sub] = res
+	}
+	p.Unlock()
+
+	res.Lock()
+	defer res.Unlock()
+
+	if res.checked.IsZero() || (p.ttl > 0 && time.Since(res.checked) > p.ttl) {
+		res.detected, res.answers = p.probe(ctx, sub)
+		res.checked = time.Now()
+	}
+	return res.detected, res.answers
+}
+
+// probe queries unlikely names within the subdomain. A wildcard is detected when at least threshold
+// probes were answered, and the answers returned by at least threshold probes are kept for matching.
+func (p *probeDetector) probe(ctx context.Context, sub string) (bool, map[string]struct{}) {
+	var answered int
+	counts := make(map[string]int)
+
+	for i := 0; i < p.probes; i++ {
+		var name string
+		for name == "" {
+			name = resolve.UnlikelyName(sub)
+		}
+
+		records := make(map[string]struct{})
+		for _, qtype := range p.enum.fwdTypes {
+			resp, err := p.enum.dnsQuery(ctx, name, qtype, p.enum.Sys.TrustedResolvers(), wildcardQueryAttempts)
+			if err != nil || resp == nil {
+				continue
+			}
+
+			for _, a := range resolve.ExtractAnswers(resp) {
+				records[strings.Trim(a.Data, ".")] = struct{}{}
+			}
+		}
+
+		if len(records) > 0 {
+			answered++
+		}
+		for data := range records {
+			counts[data]++
+		}
+	}
+	if answered < p.threshold {
+		return false, nil
+	}
+
+	answers := make(map[string]struct{})
+	for data, num := range counts {
+		if num >= p.threshold {
+			answers[data] = struct{}{}
+		}
+	}
+
+	p.enum.Config.Log.Printf("DNS wildcard detected: %d of %d probes answered: %s", answered, p.probes, "*."+sub)
+	return true, answers
+}
+
+// respMatchesAnswers returns true when the response shares an answer with the wildcard, or when
+// either of them has no answers to compare.
+func respMatchesAnswers(resp *dns.Msg, answers map[string]struct{}) bool {
+	if len(answers) == 0 || len(resp.Answer) == 0 {
+		return true
+	}
+
+	for _, a := range resolve.ExtractAnswers(resp) {
+		if _, found := answers[strings.Trim(a.Data, ".")]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// droppedLog appends the names suppressed by wildcard detection to a file.
+type droppedLog struct {
+	sync.Mutex
+	file *os.File
+}
+
+func newDroppedLog(path string) (*droppedLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the wildcard log_dropped file: %v", err)
+	}
+	return &droppedLog{file: f}, nil
+}
+
+// Write records the dropped name along with the wildcard parent and the addresses it resolved to.
+func (d *droppedLog) Write(name, parent string, addrs []string) {
+	if d == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	fmt.Fprintf(d.file, "%s\t%s\t%s\n", name, parent, strings.Join(addrs, ","))
+}
+
+func (d *droppedLog) Close() {
+	if d == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	_ = d.file.Sync()
+	_ = d.file.Close()
+}
+
+// wildcardParent returns the subdomain that contains the name within the provided domain.
+func wildcardParent(name, domain string) string {
+	labels := strings.Split(name, ".")
+	if len(labels) <= len(strings.Split(domain, ".")) {
+		return domain
+	}
+	return strings.Join(labels[1:], ".")
+}
+
+func respAddrs(resp *dns.Msg) []string {
+	var addrs []string
+
+	for _, a := range resolve.ExtractAnswers(resp) {
+		if a.Type == dns.TypeA || a.Type == dns.TypeAAAA {
+			addrs = append(addrs, a.Data)
+		}
+	}
+	return addrs
+}
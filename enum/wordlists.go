@@ -0,0 +1,144 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package enum
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/caffix/stringset"
+	"github.com/owasp-amass/config/config"
+)
+
+// The name of the data source that performs the brute forcing.
+const bruteForcingSource = "Brute Forcing"
+
+// labeledWordlist is an entry of the labeled_wordlists setting in the bruteforce section of the configuration options.
+type labeledWordlist struct {
+	Path  string
+	Label string
+}
+
+func loadLabeledWordlists(cfg *config.Config) ([]*labeledWordlist, error) {
+	bruteRaw, ok := cfg.Options["bruteforce"]
+	if !ok {
+		return nil, nil
+	}
+
+	brute, ok := bruteRaw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bruteforce is not a map[string]interface{}")
+	}
+
+	listsRaw, ok := brute["labeled_wordlists"]
+	if !ok {
+		return nil, nil
+	}
+
+	entries, ok := listsRaw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bruteforce labeled_wordlists is not an array")
+	}
+
+	var lists []*labeledWordlist
+	for _, entryRaw := range entries {
+		entry, ok := entryRaw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bruteforce labeled_wordlists item is not a map[string]interface{}")
+		}
+
+		path, ok := entry["path"].(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("bruteforce labeled_wordlists item path is not a string")
+		}
+
+		label := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if raw, found := entry["label"]; found {
+			if label, ok = raw.(string); !ok || label == "" {
+				return nil, fmt.Errorf("bruteforce labeled_wordlists item label is not a string")
+			}
+		}
+		lists = append(lists, &labeledWordlist{Path: path, Label: label})
+	}
+	return lists, nil
+}
+
+// wordlistTracker records the labeled wordlist that provided the words used to brute force each name.
+type wordlistTracker struct {
+	sync.Mutex
+	words map[string]string
+	names map[string]string
+}
+
+func newWordlistTracker() *wordlistTracker {
+	return &wordlistTracker{
+		words: make(map[string]string),
+		names: make(map[string]string),
+	}
+}
+
+// Load reads the labeled wordlists and merges the words into the brute forcing wordlist of the configuration.
+// A word found in more than one list is attributed to the first list providing it.
+func (wt *wordlistTracker) Load(cfg *config.Config, lists []*labeledWordlist) error {
+	var words []string
+
+	wt.Lock()
+	for _, list := range lists {
+		path, err := cfg.AbsPathFromConfigDir(list.Path)
+		if err != nil {
+			wt.Unlock()
+			return fmt.Errorf("failed to get absolute path for wordlist file: %w", err)
+		}
+
+		wordlist, err := config.GetListFromFile(path)
+		if err != nil {
+			wt.Unlock()
+			return fmt.Errorf("unable to load the file in the bruteforce labeled_wordlists setting: %s: %v", path, err)
+		}
+
+		for _, word := range wordlist {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word == "" {
+				continue
+			}
+			if _, found := wt.words[word]; !found {
+				wt.words[word] = list.Label
+			}
+			words = append(words, word)
+		}
+	}
+	wt.Unlock()
+
+	cfg.Lock()
+	defer cfg.Unlock()
+
+	cfg.Wordlist = stringset.Deduplicate(append(cfg.Wordlist, words...))
+	return nil
+}
+
+// Add records the label of the wordlist providing the first label of the brute forced name.
+func (wt *wordlistTracker) Add(name string) {
+	word := strings.ToLower(strings.SplitN(name, ".", 2)[0])
+
+	wt.Lock()
+	defer wt.Unlock()
+
+	if label, found := wt.words[word]; found {
+		if _, recorded := wt.names[name]; !recorded {
+			wt.names[name] = label
+		}
+	}
+}
+
+// Label returns the label of the wordlist that provided the brute forced name.
+func (wt *wordlistTracker) Label(name string) (string, bool) {
+	wt.Lock()
+	defer wt.Unlock()
+
+	label, found := wt.names[name]
+	return label, found
+}
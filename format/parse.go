@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -29,6 +30,9 @@ type ParseCIDRs []*net.IPNet
 // ParseASNs implements the flag.Value interface.
 type ParseASNs []int
 
+// ParseTags implements the flag.Value interface.
+type ParseTags map[string]string
+
 func (p *ParseStrings) String() string {
 	if p == nil {
 		return ""
@@ -206,3 +210,44 @@ func (p *ParseASNs) Set(s string) error {
 	}
 	return nil
 }
+
+func (p *ParseTags) String() string {
+	if p == nil {
+		return ""
+	}
+
+	keys := make([]string, 0, len(*p))
+	for k := range *p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			builder.WriteRune(',')
+		}
+		builder.WriteString(k + "=" + (*p)[k])
+	}
+	return builder.String()
+}
+
+// Set implements the flag.Value interface.
+func (p *ParseTags) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("tag parsing failed")
+	}
+	if *p == nil {
+		*p = make(ParseTags)
+	}
+
+	for _, tag := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(tag, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("%s is not a key=value tag", strings.TrimSpace(tag))
+		}
+		(*p)[key] = strings.TrimSpace(value)
+	}
+	return nil
+}
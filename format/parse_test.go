@@ -316,6 +316,59 @@ func TestParseASNs(t *testing.T) {
 	}
 }
 
+func TestParseTags(t *testing.T) {
+	cases := []struct {
+		label    string
+		input    string
+		ok       bool
+		expected string
+	}{
+		{
+			label: "Empty",
+			input: "",
+		}, {
+			label:    "Valid_Tags",
+			input:    "engagement=2024Q3,client=acme",
+			ok:       true,
+			expected: "client=acme,engagement=2024Q3",
+		}, {
+			label:    "Whitespace",
+			input:    " client = acme ,\tteam=red ",
+			ok:       true,
+			expected: "client=acme,team=red",
+		}, {
+			label:    "Empty_Value",
+			input:    "client=",
+			ok:       true,
+			expected: "client=",
+		}, {
+			label: "Missing_Equals",
+			input: "client=acme,engagement",
+		}, {
+			label: "Missing_Key",
+			input: "=acme",
+		},
+	}
+
+	for _, c := range cases {
+		f := func(t *testing.T) {
+			var tags ParseTags
+
+			if err := tags.Set(c.input); err != nil && c.ok {
+				t.Errorf("Got: %v; Expected: <nil>", err)
+			} else if err == nil && !c.ok {
+				t.Error("Got: <nil>; Expected: some error")
+			} else if err == nil && c.ok {
+				if got := tags.String(); got != c.expected {
+					t.Errorf("Got: %q; Expected: %q", got, c.expected)
+				}
+			}
+		}
+
+		t.Run(c.label, f)
+	}
+}
+
 func TestParseRange(t *testing.T) {
 	tests := []struct {
 		name  string
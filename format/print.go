@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	amassnet "github.com/owasp-amass/amass/v4/net"
@@ -77,11 +79,110 @@ func UpdateSummaryData(output *requests.Output, asns map[int]*ASNSummaryData) {
 	}
 }
 
+// ASNNamesData stores the names resolving into the netblocks of a discovered AS.
+type ASNNamesData struct {
+	Name      string
+	Netblocks map[string]map[string]struct{}
+}
+
+// UpdateASNNames adds the name in the requests.Output data under each netblock its addresses fall within.
+func UpdateASNNames(output *requests.Output, asns map[int]*ASNNamesData) {
+	for _, addr := range output.Addresses {
+		if addr.CIDRStr == "" {
+			continue
+		}
+
+		data, found := asns[addr.ASN]
+		if !found {
+			data = &ASNNamesData{
+				Name:      addr.Description,
+				Netblocks: make(map[string]map[string]struct{}),
+			}
+			asns[addr.ASN] = data
+		}
+
+		names, found := data.Netblocks[addr.CIDRStr]
+		if !found {
+			names = make(map[string]struct{})
+			data.Netblocks[addr.CIDRStr] = names
+		}
+		names[output.Name] = struct{}{}
+	}
+}
+
+// FprintNamesByASN outputs each AS, its netblocks and the names resolving into them, sorted by ASN.
+func FprintNamesByASN(out io.Writer, asns map[int]*ASNNamesData, demo bool) {
+	var nums []int
+	for asn := range asns {
+		nums = append(nums, asn)
+	}
+	sort.Ints(nums)
+
+	for _, asn := range nums {
+		data := asns[asn]
+		asnstr := strconv.Itoa(asn)
+		datastr := data.Name
+
+		if demo && asn > 0 {
+			asnstr = censorString(asnstr, 0, len(asnstr))
+			datastr = censorString(datastr, 0, len(datastr))
+		}
+		fmt.Fprintf(out, "%s%s %s %s\n", blue("ASN: "), yellow(asnstr), green("-"), green(datastr))
+
+		var cidrs []string
+		for cidr := range data.Netblocks {
+			cidrs = append(cidrs, cidr)
+		}
+		sort.Strings(cidrs)
+
+		for _, cidr := range cidrs {
+			cidrstr := cidr
+			if demo {
+				cidrstr = censorNetBlock(cidrstr)
+			}
+			fmt.Fprintf(out, "\t%s\n", yellow(cidrstr))
+
+			var names []string
+			for name := range data.Netblocks[cidr] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				if demo {
+					name = censorDomain(name)
+				}
+				fmt.Fprintf(out, "\t\t%s\n", green(name))
+			}
+		}
+	}
+}
+
 // PrintEnumerationSummary outputs the summary information utilized by the command-line tools.
 func PrintEnumerationSummary(total int, asns map[int]*ASNSummaryData, demo bool) {
 	FprintEnumerationSummary(color.Error, total, asns, demo)
 }
 
+// FprintCheckpointSummary outputs a single line with the counts collected so far by a running enumeration,
+// along with the rate of name discovery since the enumeration started.
+func FprintCheckpointSummary(out io.Writer, elapsed time.Duration, names, addrs, asns int) {
+	var rate float64
+	if mins := elapsed.Minutes(); mins > 0 {
+		rate = float64(names) / mins
+	}
+
+	fmt.Fprintf(out, "%s %s%s %s %s %s %s %s %s %s %s\n", green("Checkpoint after"), yellow(elapsed.Round(time.Second).String()),
+		green(":"), yellow(strconv.Itoa(names)), green("names,"), yellow(strconv.Itoa(addrs)), green("addresses,"),
+		yellow(strconv.Itoa(asns)), green("ASNs,"), yellow(strconv.FormatFloat(rate, 'f', 1, 64)), green("names per minute"))
+}
+
+// FprintProgress outputs a single line with the live counts of a running enumeration.
+func FprintProgress(out io.Writer, elapsed time.Duration, names, queries int64, sources, queued int) {
+	fmt.Fprintf(out, "%s %s%s %s %s %s %s %s %s %s %s\n", green("Progress after"), yellow(elapsed.Round(time.Second).String()),
+		green(":"), yellow(strconv.FormatInt(names, 10)), green("names,"), yellow(strconv.FormatInt(queries, 10)),
+		green("DNS queries,"), yellow(strconv.Itoa(sources)), green("active data sources,"), yellow(strconv.Itoa(queued)), green("queued"))
+}
+
 // FprintEnumerationSummary outputs the summary information utilized by the command-line tools.
 func FprintEnumerationSummary(out io.Writer, total int, asns map[int]*ASNSummaryData, demo bool) {
 	pad := func(num int, chr string) {
@@ -205,6 +306,109 @@ func OutputLineParts(out *requests.Output, addrs, demo bool) (name, ips string)
 	return
 }
 
+// RelationRecord is the JSON representation of a relation between two assets printed by the command-line tools.
+type RelationRecord struct {
+	From     string `json:"from"`
+	FromType string `json:"from_type"`
+	Relation string `json:"relation"`
+	To       string `json:"to"`
+	ToType   string `json:"to_type"`
+}
+
+// NodeRecord is the JSON representation of an asset reached by the relations of a GraphRecord.
+type NodeRecord struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+}
+
+// GraphRecord is the JSON representation of the assets reached and the relations between them.
+type GraphRecord struct {
+	Nodes     []*NodeRecord     `json:"nodes"`
+	Relations []*RelationRecord `json:"relations"`
+}
+
+// NewGraphRecord returns the JSON representation of the relations, providing each asset once as a node.
+func NewGraphRecord(rels []*RelationRecord) *GraphRecord {
+	g := &GraphRecord{
+		Nodes:     []*NodeRecord{},
+		Relations: append([]*RelationRecord{}, rels...),
+	}
+
+	ids := make(map[string]struct{})
+	for _, rel := range rels {
+		for _, n := range [][2]string{{rel.From, rel.FromType}, {rel.To, rel.ToType}} {
+			id := n[1] + ":" + n[0]
+			if _, found := ids[id]; found {
+				continue
+			}
+			ids[id] = struct{}{}
+			g.Nodes = append(g.Nodes, &NodeRecord{
+				ID:    len(g.Nodes),
+				Label: n[0],
+				Type:  n[1],
+			})
+		}
+	}
+	return g
+}
+
+// TreeRecord is the JSON representation of a name nested under its parent zone. The zones that only
+// exist as the parents of discovered names are not discovered and have no addresses.
+type TreeRecord struct {
+	Name       string          `json:"name"`
+	Discovered bool            `json:"discovered"`
+	Addresses  []AddressRecord `json:"addresses,omitempty"`
+	Children   []*TreeRecord   `json:"children"`
+}
+
+// OutputRecord is the JSON representation of a requests.Output printed by the command-line tools.
+type OutputRecord struct {
+	Name      string          `json:"name"`
+	Domain    string          `json:"domain"`
+	Addresses []AddressRecord `json:"addresses"`
+}
+
+// AddressRecord is the JSON representation of a requests.AddressInfo.
+type AddressRecord struct {
+	IP          string `json:"ip"`
+	CIDR        string `json:"cidr,omitempty"`
+	ASN         int    `json:"asn,omitempty"`
+	Description string `json:"desc,omitempty"`
+	CDN         string `json:"cdn,omitempty"`
+}
+
+// NewOutputRecord returns the JSON representation of a requests.Output, censored when demo is true.
+func NewOutputRecord(out *requests.Output, demo bool) *OutputRecord {
+	rec := &OutputRecord{
+		Name:      out.Name,
+		Domain:    out.Domain,
+		Addresses: []AddressRecord{},
+	}
+	if demo {
+		rec.Name = censorDomain(rec.Name)
+		rec.Domain = censorDomain(rec.Domain)
+	}
+
+	for _, a := range out.Addresses {
+		addr := AddressRecord{
+			IP:          a.Address.String(),
+			CIDR:        a.CIDRStr,
+			ASN:         a.ASN,
+			Description: a.Description,
+			CDN:         a.CDN,
+		}
+		if demo {
+			addr.IP = censorIP(addr.IP)
+			if addr.CIDR != "" {
+				addr.CIDR = censorNetBlock(addr.CIDR)
+			}
+		}
+		rec.Addresses = append(rec.Addresses, addr)
+	}
+	return rec
+}
+
 // DesiredAddrTypes removes undesired address types from the AddressInfo slice.
 func DesiredAddrTypes(addrs []requests.AddressInfo, ipv4, ipv6 bool) []requests.AddressInfo {
 	var kept []requests.AddressInfo
@@ -220,6 +424,26 @@ func DesiredAddrTypes(addrs []requests.AddressInfo, ipv4, ipv6 bool) []requests.
 	return kept
 }
 
+// AddrsInCIDRs removes the addresses in the AddressInfo slice that do not fall within the provided CIDRs.
+// When no CIDRs are provided, the slice is returned unchanged.
+func AddrsInCIDRs(addrs []requests.AddressInfo, cidrs []*net.IPNet) []requests.AddressInfo {
+	if len(cidrs) == 0 {
+		return addrs
+	}
+
+	var kept []requests.AddressInfo
+	for _, addr := range addrs {
+		for _, cidr := range cidrs {
+			if cidr.Contains(addr.Address) {
+				kept = append(kept, addr)
+				break
+			}
+		}
+	}
+
+	return kept
+}
+
 // InterfaceInfo returns network interface information specific to the current host.
 func InterfaceInfo() string {
 	var output string
@@ -0,0 +1,180 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package format
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/amass/v4/requests"
+)
+
+func TestAddrsInCIDRs(t *testing.T) {
+	_, cidr1, _ := net.ParseCIDR("192.0.2.0/24")
+	_, cidr2, _ := net.ParseCIDR("2001:db8::/32")
+	addrs := []requests.AddressInfo{
+		{Address: net.ParseIP("192.0.2.10")},
+		{Address: net.ParseIP("198.51.100.1")},
+		{Address: net.ParseIP("2001:db8::1")},
+	}
+
+	cases := []struct {
+		label    string
+		cidrs    []*net.IPNet
+		expected int
+	}{
+		{
+			label:    "No_CIDRs",
+			expected: 3,
+		}, {
+			label:    "IPv4_CIDR",
+			cidrs:    []*net.IPNet{cidr1},
+			expected: 1,
+		}, {
+			label:    "Both_CIDRs",
+			cidrs:    []*net.IPNet{cidr1, cidr2},
+			expected: 2,
+		},
+	}
+
+	for _, c := range cases {
+		f := func(t *testing.T) {
+			if got := AddrsInCIDRs(addrs, c.cidrs); len(got) != c.expected {
+				t.Errorf("Got: %d addresses; Expected: %d", len(got), c.expected)
+			}
+		}
+		t.Run(c.label, f)
+	}
+}
+
+func TestNamesByASN(t *testing.T) {
+	asns := make(map[int]*ASNNamesData)
+	for _, out := range []*requests.Output{
+		{
+			Name: "www.example.com",
+			Addresses: []requests.AddressInfo{
+				{Address: net.ParseIP("192.0.2.10"), CIDRStr: "192.0.2.0/24", ASN: 64500, Description: "EXAMPLE-NET"},
+			},
+		}, {
+			Name: "api.example.com",
+			Addresses: []requests.AddressInfo{
+				{Address: net.ParseIP("192.0.2.20"), CIDRStr: "192.0.2.0/24", ASN: 64500, Description: "EXAMPLE-NET"},
+				{Address: net.ParseIP("198.51.100.1"), CIDRStr: "198.51.100.0/24", ASN: 64501, Description: "OTHER-NET"},
+			},
+		}, {
+			Name:      "unresolved.example.com",
+			Addresses: []requests.AddressInfo{{Address: net.ParseIP("203.0.113.1")}},
+		},
+	} {
+		UpdateASNNames(out, asns)
+	}
+
+	if len(asns) != 2 {
+		t.Fatalf("Got: %d ASNs; Expected: 2", len(asns))
+	}
+	if names := asns[64500].Netblocks["192.0.2.0/24"]; len(names) != 2 {
+		t.Errorf("Got: %d names in the netblock; Expected: 2", len(names))
+	}
+
+	var buf bytes.Buffer
+	FprintNamesByASN(&buf, asns, false)
+	output := buf.String()
+	if strings.Contains(output, "unresolved.example.com") {
+		t.Errorf("The name without a netblock was printed")
+	}
+	if first, second := strings.Index(output, "64500"), strings.Index(output, "64501"); first == -1 || second < first {
+		t.Errorf("The ASNs were not printed in order")
+	}
+	if first, second := strings.Index(output, "api.example.com"), strings.Index(output, "www.example.com"); first == -1 || second < first {
+		t.Errorf("The names were not printed in order")
+	}
+}
+
+func TestFprintCheckpointSummary(t *testing.T) {
+	var buf bytes.Buffer
+	FprintCheckpointSummary(&buf, 10*time.Minute, 250, 40, 3)
+
+	output := buf.String()
+	for _, expected := range []string{"10m0s", "250 names", "40 addresses", "3 ASNs", "25.0 names per minute"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("The checkpoint summary %q does not contain %q", output, expected)
+		}
+	}
+
+	buf.Reset()
+	FprintCheckpointSummary(&buf, 0, 0, 0, 0)
+	if !strings.Contains(buf.String(), "0.0 names per minute") {
+		t.Errorf("The checkpoint summary without elapsed time was %q", buf.String())
+	}
+}
+
+func TestFprintProgress(t *testing.T) {
+	var buf bytes.Buffer
+	FprintProgress(&buf, 90*time.Second, 120, 4500, 7, 38)
+
+	output := buf.String()
+	for _, expected := range []string{"1m30s", "120 names", "4500 DNS queries", "7 active data sources", "38 queued"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("The progress line %q does not contain %q", output, expected)
+		}
+	}
+}
+
+func TestNewOutputRecord(t *testing.T) {
+	out := &requests.Output{
+		Name:   "www.example.com",
+		Domain: "example.com",
+		Addresses: []requests.AddressInfo{
+			{Address: net.ParseIP("192.0.2.10"), CIDRStr: "192.0.2.0/24", ASN: 64500, Description: "EXAMPLE-NET"},
+		},
+	}
+
+	rec := NewOutputRecord(out, false)
+	if rec.Name != out.Name || rec.Domain != out.Domain || len(rec.Addresses) != 1 {
+		t.Fatalf("The record %+v does not match the output", rec)
+	}
+	if a := rec.Addresses[0]; a.IP != "192.0.2.10" || a.CIDR != "192.0.2.0/24" || a.ASN != 64500 || a.Description != "EXAMPLE-NET" {
+		t.Errorf("The address record %+v does not match the output", a)
+	}
+
+	rec = NewOutputRecord(out, true)
+	if rec.Name == out.Name || strings.Contains(rec.Addresses[0].IP, "192") || strings.Contains(rec.Addresses[0].CIDR, "192") {
+		t.Errorf("The record %+v was not censored", rec)
+	}
+
+	if rec := NewOutputRecord(&requests.Output{Name: "mail.example.com"}, false); rec.Addresses == nil {
+		t.Errorf("The record without addresses has a nil slice")
+	}
+}
+
+func TestNewGraphRecord(t *testing.T) {
+	rels := []*RelationRecord{
+		{From: "www.example.com", FromType: "FQDN", Relation: "cname_record", To: "cdn.example.net", ToType: "FQDN"},
+		{From: "cdn.example.net", FromType: "FQDN", Relation: "a_record", To: "192.0.2.1", ToType: "IPAddress"},
+		{From: "mail.example.com", FromType: "FQDN", Relation: "a_record", To: "192.0.2.1", ToType: "IPAddress"},
+	}
+
+	g := NewGraphRecord(rels)
+	if len(g.Relations) != len(rels) {
+		t.Errorf("The graph has %d relations, expected %d", len(g.Relations), len(rels))
+	}
+
+	expected := []string{"www.example.com", "cdn.example.net", "192.0.2.1", "mail.example.com"}
+	if len(g.Nodes) != len(expected) {
+		t.Fatalf("The graph has %d nodes, expected %d", len(g.Nodes), len(expected))
+	}
+	for i, n := range g.Nodes {
+		if n.ID != i || n.Label != expected[i] {
+			t.Errorf("The node %+v does not match the expected ID %d and label %s", n, i, expected[i])
+		}
+	}
+
+	if g := NewGraphRecord(nil); g.Nodes == nil || g.Relations == nil {
+		t.Errorf("The empty graph has nil slices")
+	}
+}
@@ -11,9 +11,9 @@ import (
 
 	"github.com/caffix/pipeline"
 	"github.com/caffix/queue"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
 	"github.com/owasp-amass/amass/v4/net/http"
 	"github.com/owasp-amass/amass/v4/requests"
-	"golang.org/x/net/publicsuffix"
 )
 
 // activeTask is the task that handles all requests related to active methods within the pipeline.
@@ -119,9 +119,9 @@ func (a *activeTask) certEnumeration(ctx context.Context, req *requests.AddrRequ
 
 	c := a.c
 	addrinfo := requests.AddressInfo{Address: ip}
-	for _, name := range http.PullCertificateNames(ctx, req.Address, c.Config.Scope.Ports) {
+	for _, name := range http.PullCertificateNames(ctx, req.Address, c.Config.Scope.Ports, c.CertTimeout) {
 		if n := strings.TrimSpace(name); n != "" {
-			domain, err := publicsuffix.EffectiveTLDPlusOne(n)
+			domain, err := amassdns.EffectiveTLDPlusOne(n)
 			if err != nil {
 				continue
 			}
@@ -17,12 +17,12 @@ import (
 	"github.com/caffix/stringset"
 	"github.com/owasp-amass/amass/v4/datasrcs"
 	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/amass/v4/systems"
 	"github.com/owasp-amass/config/config"
 	"github.com/owasp-amass/resolve"
 	bf "github.com/tylertreat/BoomFilters"
-	"golang.org/x/net/publicsuffix"
 )
 
 const (
@@ -33,8 +33,12 @@ const (
 // Collection is the object type used to execute a open source information gathering with Amass.
 type Collection struct {
 	sync.Mutex
-	Config            *config.Config
-	Sys               systems.System
+	Config *config.Config
+	Sys    systems.System
+	// CertConcurrency is the number of addresses having their certificates pulled at once in the active mode
+	CertConcurrency int
+	// CertTimeout is the time allowed for each TLS handshake performed to pull the certificates
+	CertTimeout       time.Duration
 	ctx               context.Context
 	srcs              []service.Service
 	Output            chan *requests.Output
@@ -85,7 +89,11 @@ func (c *Collection) HostedDomains(ctx context.Context) error {
 	var stages []pipeline.Stage
 	stages = append(stages, pipeline.DynamicPool("", c.makeDNSTaskFunc(), maxDnsPipelineTasks))
 	if c.Config.Active {
-		stages = append(stages, pipeline.FIFO("", newActiveTask(c, maxActivePipelineTasks)))
+		max := c.CertConcurrency
+		if max <= 0 {
+			max = maxActivePipelineTasks
+		}
+		stages = append(stages, pipeline.FIFO("", newActiveTask(c, max)))
 	}
 	stages = append(stages, pipeline.FIFO("filter", c.makeFilterTaskFunc()))
 
@@ -138,12 +146,15 @@ func (c *Collection) makeDNSTaskFunc() pipeline.TaskFunc {
 			return nil, nil
 		}
 
-		msg := resolve.ReverseMsg(req.Address)
+		msg := amassdns.ReverseMsg(req.Address)
 		if msg == nil {
 			return nil, nil
 		}
 
 		addrinfo := requests.AddressInfo{Address: ip}
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			return nil, nil
+		}
 		resp, err := c.Sys.TrustedResolvers().QueryBlocking(ctx, msg)
 		if err == nil {
 			ans := resolve.ExtractAnswers(resp)
@@ -274,7 +285,7 @@ func (c *Collection) collect(req *requests.WhoisRequest) {
 	c.timeChan <- time.Now()
 
 	for _, name := range req.NewDomains {
-		if d, err := publicsuffix.EffectiveTLDPlusOne(name); err == nil && !c.filter.TestAndAdd([]byte(d)) {
+		if d, err := amassdns.EffectiveTLDPlusOne(name); err == nil && !c.filter.TestAndAdd([]byte(d)) {
 			c.Output <- &requests.Output{
 				Name:   d,
 				Domain: d,
@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package intel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// CertConcurrency returns the cert_concurrency option, the number of addresses having their
+// certificates pulled at once. Zero is returned when the option is not set.
+func CertConcurrency(cfg *config.Config) (int, error) {
+	num, err := certOption(cfg, "cert_concurrency")
+	if err != nil {
+		return 0, err
+	}
+	return int(num), nil
+}
+
+// CertTimeout returns the cert_timeout option, the number of seconds allowed for each TLS handshake
+// performed to pull the certificates. Zero is returned when the option is not set.
+func CertTimeout(cfg *config.Config) (time.Duration, error) {
+	secs, err := certOption(cfg, "cert_timeout")
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func certOption(cfg *config.Config, key string) (float64, error) {
+	raw, ok := cfg.Options[key]
+	if !ok {
+		return 0, nil
+	}
+
+	var num float64
+	switch v := raw.(type) {
+	case int:
+		num = float64(v)
+	case float64:
+		num = v
+	default:
+		return 0, errors.New(key + " is not a number")
+	}
+	if num < 0 {
+		return 0, errors.New(key + " must be a positive number")
+	}
+	return num, nil
+}
@@ -0,0 +1,56 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package net
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrDNSBudgetExhausted is returned for DNS queries attempted once the query budget has been used.
+var ErrDNSBudgetExhausted = errors.New("the DNS query budget has been exhausted")
+
+var (
+	dnsBudget  atomic.Int64
+	dnsQueries atomic.Int64
+)
+
+// SetDNSBudget sets the maximum number of DNS queries issued by the process and resets the count
+// of queries. A value of zero removes the budget.
+func SetDNSBudget(max int64) {
+	if max < 0 {
+		max = 0
+	}
+
+	dnsBudget.Store(max)
+	dnsQueries.Store(0)
+}
+
+// TakeDNSQuery counts a DNS query about to be issued. ErrDNSBudgetExhausted is returned, and the
+// query must not be sent, once the budget has been used.
+func TakeDNSQuery() error {
+	num := dnsQueries.Add(1)
+
+	if max := dnsBudget.Load(); max > 0 && num > max {
+		return ErrDNSBudgetExhausted
+	}
+	return nil
+}
+
+// DNSQueries returns the number of DNS queries issued since the budget was set.
+func DNSQueries() int64 {
+	num := dnsQueries.Load()
+
+	if max := dnsBudget.Load(); max > 0 && num > max {
+		return max
+	}
+	return num
+}
+
+// DNSBudgetExhausted returns true once the DNS query budget has been used.
+func DNSBudgetExhausted() bool {
+	max := dnsBudget.Load()
+	return max > 0 && dnsQueries.Load() >= max
+}
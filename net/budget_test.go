@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package net
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestDNSBudget(t *testing.T) {
+	defer SetDNSBudget(0)
+
+	SetDNSBudget(0)
+	for i := 0; i < 10; i++ {
+		if err := TakeDNSQuery(); err != nil {
+			t.Errorf("Returned an error without a budget: %v", err)
+		}
+	}
+	if DNSBudgetExhausted() {
+		t.Errorf("Reported an exhausted budget without a budget")
+	}
+
+	SetDNSBudget(100)
+	if num := DNSQueries(); num != 0 {
+		t.Errorf("Setting the budget did not reset the count, which is %d", num)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var refused int
+	for i := 0; i < 150; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := TakeDNSQuery(); errors.Is(err, ErrDNSBudgetExhausted) {
+				mu.Lock()
+				refused++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if refused != 50 {
+		t.Errorf("Refused %d queries instead of 50", refused)
+	}
+	if num := DNSQueries(); num != 100 {
+		t.Errorf("Counted %d queries instead of 100", num)
+	}
+	if !DNSBudgetExhausted() {
+		t.Errorf("Failed to report the exhausted budget")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"net/netip"
+	"sync"
+
+	mdns "github.com/miekg/dns"
+	"github.com/owasp-amass/resolve"
+)
+
+var (
+	subnetLock   sync.Mutex
+	clientSubnet netip.Prefix
+)
+
+// SetClientSubnet sets the EDNS0 client subnet sent with the queries built by QueryMsg and ReverseMsg.
+// The zero prefix restores the 0.0.0.0/0 subnet that hides the location of the client.
+func SetClientSubnet(prefix netip.Prefix) {
+	subnetLock.Lock()
+	defer subnetLock.Unlock()
+
+	clientSubnet = prefix.Masked()
+}
+
+// ClientSubnet returns the EDNS0 client subnet set by SetClientSubnet.
+func ClientSubnet() netip.Prefix {
+	subnetLock.Lock()
+	defer subnetLock.Unlock()
+
+	return clientSubnet
+}
+
+// QueryMsg returns the query built by resolve.QueryMsg, carrying the EDNS0 client subnet that was set.
+func QueryMsg(name string, qtype uint16) *mdns.Msg {
+	return withClientSubnet(resolve.QueryMsg(name, qtype))
+}
+
+// ReverseMsg returns the PTR query built by resolve.ReverseMsg, carrying the EDNS0 client subnet that was set.
+// Nil is returned when the address cannot be parsed.
+func ReverseMsg(addr string) *mdns.Msg {
+	if msg := resolve.ReverseMsg(addr); msg != nil {
+		return withClientSubnet(msg)
+	}
+	return nil
+}
+
+func withClientSubnet(msg *mdns.Msg) *mdns.Msg {
+	prefix := ClientSubnet()
+	if !prefix.IsValid() {
+		return msg
+	}
+
+	family := uint16(1)
+	if prefix.Addr().Is6() {
+		family = 2
+	}
+
+	for _, rr := range msg.Extra {
+		opt, ok := rr.(*mdns.OPT)
+		if !ok {
+			continue
+		}
+
+		for _, o := range opt.Option {
+			if subnet, ok := o.(*mdns.EDNS0_SUBNET); ok {
+				subnet.Family = family
+				subnet.SourceNetmask = uint8(prefix.Bits())
+				subnet.Address = prefix.Addr().AsSlice()
+			}
+		}
+	}
+	return msg
+}
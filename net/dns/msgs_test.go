@@ -0,0 +1,71 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+)
+
+func msgClientSubnet(t *testing.T, msg *mdns.Msg) *mdns.EDNS0_SUBNET {
+	if msg == nil {
+		t.Fatal("the message was not built")
+	}
+
+	for _, rr := range msg.Extra {
+		if opt, ok := rr.(*mdns.OPT); ok {
+			for _, o := range opt.Option {
+				if subnet, ok := o.(*mdns.EDNS0_SUBNET); ok {
+					return subnet
+				}
+			}
+		}
+	}
+
+	t.Fatal("the message has no EDNS0 client subnet option")
+	return nil
+}
+
+func TestClientSubnet(t *testing.T) {
+	defer SetClientSubnet(netip.Prefix{})
+
+	tests := []struct {
+		prefix  string
+		family  uint16
+		netmask uint8
+		addr    string
+	}{
+		{"", 1, 0, "0.0.0.0"},
+		{"203.0.113.77/24", 1, 24, "203.0.113.0"},
+		{"2001:db8:1234::/48", 2, 48, "2001:db8:1234::"},
+	}
+
+	for _, test := range tests {
+		var prefix netip.Prefix
+		if test.prefix != "" {
+			prefix = netip.MustParsePrefix(test.prefix)
+		}
+		SetClientSubnet(prefix)
+
+		for _, msg := range []*mdns.Msg{QueryMsg("www.owasp.org", mdns.TypeA), ReverseMsg("192.0.2.10")} {
+			subnet := msgClientSubnet(t, msg)
+
+			if subnet.Family != test.family || subnet.SourceNetmask != test.netmask ||
+				!subnet.Address.Equal(net.ParseIP(test.addr)) {
+				t.Errorf("%q: got family %d, netmask %d and address %s", test.prefix,
+					subnet.Family, subnet.SourceNetmask, subnet.Address)
+			}
+		}
+	}
+}
+
+func TestReverseMsgInvalidAddress(t *testing.T) {
+	if msg := ReverseMsg("not an address"); msg != nil {
+		t.Errorf("expected no message for an invalid address")
+	}
+}
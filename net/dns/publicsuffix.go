@@ -0,0 +1,145 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// suffixList holds the rules of a Public Suffix List file, keyed by the suffix each rule applies to.
+type suffixList struct {
+	rules      map[string]struct{}
+	wildcards  map[string]struct{}
+	exceptions map[string]struct{}
+}
+
+var (
+	suffixLock sync.RWMutex
+	suffixes   *suffixList
+)
+
+// SetPublicSuffixFile loads the Public Suffix List file used by EffectiveTLDPlusOne ahead of the
+// list embedded in the binary. An empty path removes the list loaded previously.
+func SetPublicSuffixFile(path string) error {
+	var list *suffixList
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open the public suffix list: %v", err)
+		}
+		defer f.Close()
+
+		list, err = parseSuffixList(f)
+		if err != nil {
+			return fmt.Errorf("failed to parse the public suffix list %s: %v", path, err)
+		}
+	}
+
+	suffixLock.Lock()
+	defer suffixLock.Unlock()
+
+	suffixes = list
+	return nil
+}
+
+// EffectiveTLDPlusOne returns the registrable domain of the name, which is the public suffix plus
+// one label. The rules of the file loaded by SetPublicSuffixFile are applied first, and the
+// embedded list is only consulted when none of them match the name. The embedded list treats the
+// last label of an unknown TLD as the public suffix, so no fallback on NS queries is needed and the
+// derivation remains offline.
+func EffectiveTLDPlusOne(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+
+	suffixLock.RLock()
+	list := suffixes
+	suffixLock.RUnlock()
+
+	if list == nil {
+		return publicsuffix.EffectiveTLDPlusOne(name)
+	}
+
+	suffix, found := list.publicSuffix(name)
+	if !found {
+		return publicsuffix.EffectiveTLDPlusOne(name)
+	}
+	if len(name) <= len(suffix) {
+		return "", fmt.Errorf("cannot derive eTLD+1 for domain %q", name)
+	}
+
+	i := len(name) - len(suffix) - 1
+	if name[i] != '.' {
+		return "", fmt.Errorf("invalid public suffix %q for domain %q", suffix, name)
+	}
+	return name[1+strings.LastIndex(name[:i], "."):], nil
+}
+
+// parseSuffixList reads the rules in the Public Suffix List format: one rule per line, with the
+// comments starting with two slashes, the wildcard rules with an asterisk label and the exception
+// rules with an exclamation mark.
+func parseSuffixList(r io.Reader) (*suffixList, error) {
+	list := &suffixList{
+		rules:      make(map[string]struct{}),
+		wildcards:  make(map[string]struct{}),
+		exceptions: make(map[string]struct{}),
+	}
+
+	var num int
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+
+		rule := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			list.exceptions[rule[1:]] = struct{}{}
+		case strings.HasPrefix(rule, "*."):
+			list.wildcards[rule[2:]] = struct{}{}
+		default:
+			list.rules[rule] = struct{}{}
+		}
+		num++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if num == 0 {
+		return nil, fmt.Errorf("the list does not contain any rules")
+	}
+	return list, nil
+}
+
+// publicSuffix returns the public suffix of the name selected by the prevailing rule, which is the
+// matching rule with the most labels. False is returned when none of the rules match the name.
+func (l *suffixList) publicSuffix(name string) (string, bool) {
+	labels := strings.Split(name, ".")
+
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+
+		if _, found := l.exceptions[suffix]; found {
+			return strings.Join(labels[i+1:], "."), true
+		}
+		if _, found := l.rules[suffix]; found {
+			return suffix, true
+		}
+		if i+1 < len(labels) {
+			if _, found := l.wildcards[strings.Join(labels[i+1:], ".")]; found {
+				return suffix, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSuffixList = `// ===BEGIN ICANN DOMAINS===
+uk
+co.uk
+*.ck
+!www.ck
+
+// ===BEGIN PRIVATE DOMAINS===
+internal.example
+`
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "public_suffix_list.dat")
+	if err := os.WriteFile(path, []byte(testSuffixList), 0600); err != nil {
+		t.Fatalf("Failed to write the public suffix list: %v", err)
+	}
+	if err := SetPublicSuffixFile(path); err != nil {
+		t.Fatalf("Failed to load the public suffix list: %v", err)
+	}
+	defer func() { _ = SetPublicSuffixFile("") }()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"www.owasp.co.uk", "owasp.co.uk"},
+		{"WWW.OWASP.CO.UK.", "owasp.co.uk"},
+		{"owasp.uk", "owasp.uk"},
+		{"www.owasp.foo.ck", "owasp.foo.ck"},
+		{"a.www.ck", "www.ck"},
+		{"api.team.internal.example", "team.internal.example"},
+		// Names not matched by the loaded rules are handled by the embedded list
+		{"www.owasp.org", "owasp.org"},
+		{"www.owasp.com.au", "owasp.com.au"},
+	}
+
+	for _, test := range tests {
+		if got, err := EffectiveTLDPlusOne(test.name); err != nil || got != test.expected {
+			t.Errorf("Name %s: expected %s, got %s (%v)", test.name, test.expected, got, err)
+		}
+	}
+
+	for _, bad := range []string{"co.uk", "foo.ck"} {
+		if got, err := EffectiveTLDPlusOne(bad); err == nil {
+			t.Errorf("The public suffix %s returned %s without an error", bad, got)
+		}
+	}
+}
+
+func TestSetPublicSuffixFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := SetPublicSuffixFile(filepath.Join(dir, "missing.dat")); err == nil {
+		t.Errorf("A missing public suffix list did not return an error")
+	}
+
+	empty := filepath.Join(dir, "empty.dat")
+	if err := os.WriteFile(empty, []byte("// only comments\n\n"), 0600); err != nil {
+		t.Fatalf("Failed to write the public suffix list: %v", err)
+	}
+	if err := SetPublicSuffixFile(empty); err == nil {
+		t.Errorf("A public suffix list without rules did not return an error")
+	}
+
+	if got, err := EffectiveTLDPlusOne("www.owasp.co.uk"); err != nil || got != "owasp.co.uk" {
+		t.Errorf("The embedded list was not used: got %s (%v)", got, err)
+	}
+}
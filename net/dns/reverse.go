@@ -0,0 +1,129 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+
+	mdns "github.com/miekg/dns"
+	amassnet "github.com/owasp-amass/amass/v4/net"
+	"github.com/owasp-amass/resolve"
+)
+
+// The number of attempts made by the resolvers for each PTR query of a reverse DNS sweep.
+const ptrQueryAttempts = 3
+
+// ErrNoPTRRecord is returned in the result of an address without a PTR record.
+var ErrNoPTRRecord = errors.New("the address has no PTR record")
+
+// PTRResult is the outcome of the reverse DNS lookup of an address performed by ReverseSweep.
+type PTRResult struct {
+	Addr netip.Addr
+	Name string
+	Err  error
+}
+
+// ReverseName returns the name queried for the PTR record of the address, which is
+// within in-addr.arpa for IPv4 addresses and ip6.arpa for IPv6 addresses.
+func ReverseName(addr netip.Addr) string {
+	addr = addr.Unmap()
+
+	if addr.Is4() {
+		return ReverseIP(addr.String()) + ".in-addr.arpa"
+	}
+	return IPv6NibbleFormat(addr.String()) + ".ip6.arpa"
+}
+
+// ReverseSweep performs the PTR lookups of the addresses through the resolver pool, with at most
+// concurrency lookups in flight, and streams a result for each address in the order they complete.
+// The channel is closed once all the lookups finish, or early when the context is cancelled.
+func ReverseSweep(ctx context.Context, pool *resolve.Resolvers, addrs []netip.Addr, concurrency int) <-chan *PTRResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ch := make(chan *PTRResult, concurrency)
+	go func() {
+		defer close(ch)
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+	loop:
+		for _, addr := range addrs {
+			// Check the context first, since select picks randomly among the ready cases
+			if ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				break loop
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(addr netip.Addr) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				res := reverseLookup(ctx, pool, addr)
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case <-ctx.Done():
+				case ch <- res:
+				}
+			}(addr)
+		}
+		wg.Wait()
+	}()
+	return ch
+}
+
+func reverseLookup(ctx context.Context, pool *resolve.Resolvers, addr netip.Addr) *PTRResult {
+	res := &PTRResult{Addr: addr}
+	msg := QueryMsg(ReverseName(addr), mdns.TypePTR)
+
+	for i := 0; i < ptrQueryAttempts; i++ {
+		if err := amassnet.TakeDNSQuery(); err != nil {
+			res.Err = err
+			return res
+		}
+
+		if err := amassnet.WaitForEgress(ctx); err != nil {
+			res.Err = err
+			return res
+		}
+		resp, err := pool.QueryBlocking(ctx, msg)
+		if err != nil {
+			res.Err = err
+			if ctx.Err() != nil {
+				return res
+			}
+			continue
+		}
+		if resp.Rcode == mdns.RcodeNameError {
+			res.Err = ErrNoPTRRecord
+			return res
+		}
+		if resp.Rcode != mdns.RcodeSuccess {
+			res.Err = fmt.Errorf("the PTR query returned %s", mdns.RcodeToString[resp.Rcode])
+			continue
+		}
+
+		res.Err = ErrNoPTRRecord
+		if records := resolve.AnswersByType(resolve.ExtractAnswers(resp), mdns.TypePTR); len(records) > 0 {
+			res.Name = strings.ToLower(resolve.RemoveLastDot(records[0].Data))
+			res.Err = nil
+		}
+		return res
+	}
+	return res
+}
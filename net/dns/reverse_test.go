@@ -0,0 +1,112 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/owasp-amass/resolve"
+)
+
+func TestReverseName(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{"192.0.2.10", "10.2.0.192.in-addr.arpa"},
+		{"::ffff:192.0.2.10", "10.2.0.192.in-addr.arpa"},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+	}
+
+	for _, test := range tests {
+		if name := ReverseName(netip.MustParseAddr(test.addr)); name != test.expected {
+			t.Errorf("Address %s: expected %s, got %s", test.addr, test.expected, name)
+		}
+	}
+}
+
+func TestReverseSweep(t *testing.T) {
+	names := map[string]string{
+		"10.2.0.192.in-addr.arpa.": "www.owasp.org.",
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.": "v6.owasp.org.",
+	}
+
+	mdns.HandleFunc("arpa.", func(w mdns.ResponseWriter, req *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(req)
+
+		if target, found := names[req.Question[0].Name]; found {
+			m.Answer = append(m.Answer, &mdns.PTR{
+				Hdr: mdns.RR_Header{Name: req.Question[0].Name, Rrtype: mdns.TypePTR, Class: mdns.ClassINET, Ttl: 60},
+				Ptr: target,
+			})
+		} else {
+			m.SetRcode(req, mdns.RcodeNameError)
+		}
+		_ = w.WriteMsg(m)
+	})
+	defer mdns.HandleRemove("arpa.")
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open the UDP socket: %v", err)
+	}
+	s := &mdns.Server{PacketConn: pc}
+	go func() { _ = s.ActivateAndServe() }()
+	defer func() { _ = s.Shutdown() }()
+
+	pool := resolve.NewResolvers()
+	_ = pool.AddResolvers(100, pc.LocalAddr().String())
+	pool.SetTimeout(time.Second)
+	defer pool.Stop()
+
+	addrs := []netip.Addr{
+		netip.MustParseAddr("192.0.2.10"),
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("192.0.2.11"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results := make(map[netip.Addr]*PTRResult)
+	for res := range ReverseSweep(ctx, pool, addrs, 2) {
+		results[res.Addr] = res
+	}
+	if len(results) != len(addrs) {
+		t.Fatalf("Expected %d results, got %d", len(addrs), len(results))
+	}
+	if res := results[addrs[0]]; res.Err != nil || res.Name != "www.owasp.org" {
+		t.Errorf("Address %s: expected www.owasp.org, got %s: %v", res.Addr, res.Name, res.Err)
+	}
+	if res := results[addrs[1]]; res.Err != nil || res.Name != "v6.owasp.org" {
+		t.Errorf("Address %s: expected v6.owasp.org, got %s: %v", res.Addr, res.Name, res.Err)
+	}
+	if res := results[addrs[2]]; !errors.Is(res.Err, ErrNoPTRRecord) {
+		t.Errorf("Address %s: expected ErrNoPTRRecord, got %v", res.Addr, res.Err)
+	}
+}
+
+func TestReverseSweepCancelled(t *testing.T) {
+	pool := resolve.NewResolvers()
+	defer pool.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var count int
+	for range ReverseSweep(ctx, pool, []netip.Addr{netip.MustParseAddr("192.0.2.10")}, 1) {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("Expected no results after the context was cancelled, got %d", count)
+	}
+}
@@ -53,6 +53,48 @@ var (
 // DefaultClient is the same HTTP client used by the package methods.
 var DefaultClient *http.Client
 
+// noRedirectClient shares the transport of the DefaultClient, but returns the redirect responses.
+var noRedirectClient *http.Client
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum size and truncation is disabled.
+var ErrResponseTooLarge = errors.New("the response body exceeded the maximum size")
+
+var (
+	maxRespLock     sync.Mutex
+	maxRespBytes    int64
+	maxRespTruncate bool
+)
+
+// SetMaxResponseBytes sets the maximum number of bytes read from a response body. When the truncate
+// argument is true, larger bodies are truncated, otherwise RequestWebPage returns ErrResponseTooLarge.
+// A maximum of zero removes the limit.
+func SetMaxResponseBytes(max int64, truncate bool) {
+	maxRespLock.Lock()
+	defer maxRespLock.Unlock()
+
+	maxRespBytes = max
+	maxRespTruncate = truncate
+}
+
+// SetTLSConfig sets the certificate authorities used to verify the servers contacted by the HTTP client.
+// A nil pool selects the system roots, and verification is skipped when the insecure argument is true.
+func SetTLSConfig(roots *x509.CertPool, insecure bool) {
+	if t, ok := DefaultClient.Transport.(*http.Transport); ok {
+		t.TLSClientConfig = &tls.Config{
+			RootCAs:            roots,
+			InsecureSkipVerify: insecure,
+		}
+		t.CloseIdleConnections()
+	}
+}
+
+func maxResponseBytes() (int64, bool) {
+	maxRespLock.Lock()
+	defer maxRespLock.Unlock()
+
+	return maxRespBytes, maxRespTruncate
+}
+
 // Header represents the HTTP headers for requests and responses.
 type Header map[string]string
 
@@ -76,6 +118,8 @@ type Response struct {
 	Body       string
 	Length     int64
 	TLS        *tls.ConnectionState
+	// Truncated is true when the body was cut off at the maximum response size
+	Truncated bool
 }
 
 // BasicAuth contains the data used for HTTP basic authentication.
@@ -100,6 +144,14 @@ func init() {
 		},
 		Jar: jar,
 	}
+	noRedirectClient = &http.Client{
+		Timeout:   httpTimeout,
+		Transport: DefaultClient.Transport,
+		Jar:       jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
 
 	switch runtime.GOOS {
 	case "windows":
@@ -150,10 +202,23 @@ func ReqToAmassRequest(req *http.Request) *Request {
 }
 
 // RespToAmassResponse converts a net/http Response to an Amass Response.
+// The body is truncated when it exceeds the maximum response size.
 func RespToAmassResponse(resp *http.Response) *Response {
 	var body string
+	var truncated bool
 	if resp.Body != nil {
-		if b, err := io.ReadAll(resp.Body); err == nil {
+		var r io.Reader = resp.Body
+
+		max, _ := maxResponseBytes()
+		if max > 0 {
+			// Read an extra byte to detect bodies exceeding the maximum
+			r = io.LimitReader(resp.Body, max+1)
+		}
+		if b, err := io.ReadAll(r); err == nil {
+			if max > 0 && int64(len(b)) > max {
+				b = b[:max]
+				truncated = true
+			}
 			body = string(b)
 		}
 		_ = resp.Body.Close()
@@ -169,6 +234,7 @@ func RespToAmassResponse(resp *http.Response) *Response {
 		Body:       body,
 		Length:     resp.ContentLength,
 		TLS:        resp.TLS,
+		Truncated:  truncated,
 	}
 }
 
@@ -223,11 +289,59 @@ func RequestWebPage(ctx context.Context, r *Request) (*Response, error) {
 		req.Header.Set(k, v)
 	}
 
+	if err := amassnet.WaitForEgress(ctx); err != nil {
+		return nil, err
+	}
+
 	resp, err := DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	return RespToAmassResponse(resp), nil
+
+	out := RespToAmassResponse(resp)
+	if _, truncate := maxResponseBytes(); out.Truncated && !truncate {
+		return nil, ErrResponseTooLarge
+	}
+	return out, nil
+}
+
+// ProbeURL sends a GET request to the URL and returns the status and headers of the response.
+// The body is not read. When follow is false, the redirect response itself is returned.
+func ProbeURL(ctx context.Context, u string, follow bool) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", Accept)
+	req.Header.Set("Accept-Language", AcceptLang)
+
+	if err := amassnet.WaitForEgress(ctx); err != nil {
+		return nil, err
+	}
+
+	client := DefaultClient
+	if !follow {
+		client = noRedirectClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return &Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		ProtoMajor: resp.ProtoMajor,
+		ProtoMinor: resp.ProtoMinor,
+		Header:     HdrToAmassHeader(resp.Header),
+		Length:     resp.ContentLength,
+		TLS:        resp.TLS,
+	}, nil
 }
 
 // Crawl will spider the web page at the URL argument looking while staying within the scope provided.
@@ -334,33 +448,45 @@ func whichDomain(name string, scope []string) string {
 	return ""
 }
 
-// PullCertificateNames attempts to pull a cert from one or more ports on an IP.
-func PullCertificateNames(ctx context.Context, addr string, ports []int) []string {
+// PullCertificateNames attempts to pull a cert from one or more ports on an IP. Each connection
+// is given the timeout to complete the TLS handshake, or the default timeout when it is zero.
+func PullCertificateNames(ctx context.Context, addr string, ports []int, timeout time.Duration) []string {
+	if timeout <= 0 {
+		timeout = handshakeTimeout
+	}
+
 	var names []string
 	// check hosts for certificates that contain subdomain names
 	for _, port := range ports {
-		if c, err := TLSConn(ctx, addr, port); err == nil {
+		select {
+		case <-ctx.Done():
+			return names
+		default:
+		}
+
+		if c, err := tlsConn(ctx, addr, port, timeout); err == nil {
 			// get the correct certificate in the chain
 			certChain := c.ConnectionState().PeerCertificates
 			// create the new requests from names found within the cert
 			names = append(names, NamesFromCert(certChain[0])...)
 			c.Close()
 		}
-
-		select {
-		case <-ctx.Done():
-			return names
-		default:
-		}
 	}
 	return names
 }
 
 // TLSConn attempts to make a TLS connection with the host on the given port.
 func TLSConn(ctx context.Context, host string, port int) (*tls.Conn, error) {
+	return tlsConn(ctx, host, port, handshakeTimeout)
+}
+
+func tlsConn(ctx context.Context, host string, port int, timeout time.Duration) (*tls.Conn, error) {
 	// set the maximum time allowed for making the connection
-	tCtx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	tCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
+	if err := amassnet.WaitForEgress(tCtx); err != nil {
+		return nil, err
+	}
 	// obtain the connection
 	conn, err := amassnet.DialContext(tCtx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
 	if err != nil {
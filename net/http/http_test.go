@@ -6,6 +6,7 @@ package http
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
@@ -147,6 +148,80 @@ func TestRequestWebPage(t *testing.T) {
 	}
 }
 
+func TestMaxResponseBytes(t *testing.T) {
+	body := strings.Repeat("a", 100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+	defer SetMaxResponseBytes(0, false)
+
+	SetMaxResponseBytes(100, true)
+	resp, err := RequestWebPage(context.TODO(), &Request{URL: ts.URL})
+	if err != nil || resp.Body != body || resp.Truncated {
+		t.Errorf("The response at the maximum size was modified")
+	}
+
+	SetMaxResponseBytes(10, true)
+	resp, err = RequestWebPage(context.TODO(), &Request{URL: ts.URL})
+	if err != nil || resp.Body != body[:10] || !resp.Truncated {
+		t.Errorf("Failed to truncate the response exceeding the maximum size")
+	}
+
+	SetMaxResponseBytes(10, false)
+	resp, err = RequestWebPage(context.TODO(), &Request{URL: ts.URL})
+	if err != ErrResponseTooLarge || resp != nil {
+		t.Errorf("Failed to return an error for the response exceeding the maximum size")
+	}
+}
+
+func TestSetTLSConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secure")
+	}))
+	defer ts.Close()
+	defer SetTLSConfig(nil, true)
+
+	SetTLSConfig(x509.NewCertPool(), false)
+	if _, err := RequestWebPage(context.TODO(), &Request{URL: ts.URL}); err == nil {
+		t.Errorf("The certificate signed by an unknown authority was accepted")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	SetTLSConfig(pool, false)
+	if resp, err := RequestWebPage(context.TODO(), &Request{URL: ts.URL}); err != nil || resp.Body != "secure" {
+		t.Errorf("Failed to verify the certificate using the provided authorities: %v", err)
+	}
+
+	SetTLSConfig(x509.NewCertPool(), true)
+	if _, err := RequestWebPage(context.TODO(), &Request{URL: ts.URL}); err != nil {
+		t.Errorf("The certificate was verified when insecure: %v", err)
+	}
+}
+
+func TestProbeURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.Header().Set("Server", "amass-test")
+		fmt.Fprint(w, "login")
+	}))
+	defer ts.Close()
+
+	resp, err := ProbeURL(context.TODO(), ts.URL, false)
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Errorf("Failed to return the redirect response: %v", err)
+	}
+
+	resp, err = ProbeURL(context.TODO(), ts.URL, true)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header["Server"] != "amass-test" {
+		t.Errorf("Failed to follow the redirect to the final response: %v", err)
+	}
+}
+
 func TestCrawl(t *testing.T) {
 	re, err := regexp.Compile(amassdns.AnySubdomainRegexString())
 	if err != nil {
@@ -248,14 +323,14 @@ func TestPullCertificateNames(t *testing.T) {
 		t.Errorf("Failed to extract a valid IP address from the DNS response")
 	}
 
-	if names := PullCertificateNames(context.Background(), ip.String(), []int{443}); len(names) == 0 {
+	if names := PullCertificateNames(context.Background(), ip.String(), []int{443}, 0); len(names) == 0 {
 		t.Errorf("Failed to obtain names from a certificate from address %s", ip.String())
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	if names := PullCertificateNames(ctx, ip.String(), []int{443}); len(names) != 0 {
+	if names := PullCertificateNames(ctx, ip.String(), []int{443}, 0); len(names) != 0 {
 		t.Errorf("Failed to detect the expired context")
 	}
 }
@@ -293,3 +368,31 @@ func TestCleanName(t *testing.T) {
 		}
 	}
 }
+
+func TestPullCertificateNamesTimeout(t *testing.T) {
+	// The listener accepts the connections, but never completes the TLS handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the listener: %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	start := time.Now()
+	if names := PullCertificateNames(context.Background(), "127.0.0.1", []int{port}, 100*time.Millisecond); len(names) != 0 {
+		t.Errorf("Obtained names without a TLS handshake")
+	}
+	if elapsed := time.Since(start); elapsed >= handshakeTimeout {
+		t.Errorf("The handshake timeout was not applied, the pull took %v", elapsed)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RDAPBootstrapURL is the RDAP service used to locate the authoritative server for a domain name.
+var RDAPBootstrapURL = "https://rdap.org/domain/"
+
+type rdapEntity struct {
+	Roles      []string      `json:"roles"`
+	VCardArray []interface{} `json:"vcardArray"`
+	Entities   []rdapEntity  `json:"entities"`
+}
+
+type rdapDomain struct {
+	Entities []rdapEntity `json:"entities"`
+}
+
+// RDAPRegistrar returns the name of the registrar for the provided registered domain name.
+func RDAPRegistrar(ctx context.Context, domain string) (string, error) {
+	resp, err := RequestWebPage(ctx, &Request{
+		URL:    RDAPBootstrapURL + strings.ToLower(strings.TrimSpace(domain)),
+		Header: Header{"Accept": "application/rdap+json"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("RDAP request for %s returned status %s", domain, resp.Status)
+	}
+
+	registrar := registrarFromRDAP(resp.Body)
+	if registrar == "" {
+		return "", fmt.Errorf("RDAP response for %s did not identify a registrar", domain)
+	}
+	return registrar, nil
+}
+
+func registrarFromRDAP(body string) string {
+	var d rdapDomain
+
+	if err := json.Unmarshal([]byte(body), &d); err != nil {
+		return ""
+	}
+	return findRDAPRegistrar(d.Entities)
+}
+
+func findRDAPRegistrar(entities []rdapEntity) string {
+	for _, e := range entities {
+		for _, role := range e.Roles {
+			if role != "registrar" {
+				continue
+			}
+			if name := vcardFullName(e.VCardArray); name != "" {
+				return name
+			}
+		}
+		if name := findRDAPRegistrar(e.Entities); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// The jCard format is ["vcard", [["fn", {}, "text", "NAME"], ...]].
+func vcardFullName(vcard []interface{}) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+
+	props, ok := vcard[1].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, p := range props {
+		prop, ok := p.([]interface{})
+		if !ok || len(prop) < 4 {
+			continue
+		}
+		if key, ok := prop[0].(string); !ok || key != "fn" {
+			continue
+		}
+		if name, ok := prop[3].(string); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testRDAPResponse = `{
+	"objectClassName": "domain",
+	"ldhName": "OWASP.ORG",
+	"entities": [
+		{
+			"objectClassName": "entity",
+			"roles": ["registrar"],
+			"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar, Inc."]]],
+			"entities": [
+				{
+					"roles": ["abuse"],
+					"vcardArray": ["vcard", [["fn", {}, "text", "Abuse Contact"]]]
+				}
+			]
+		}
+	]
+}`
+
+func TestRDAPRegistrar(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/owasp.org" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, testRDAPResponse)
+	}))
+	defer ts.Close()
+
+	orig := RDAPBootstrapURL
+	RDAPBootstrapURL = ts.URL + "/domain/"
+	defer func() { RDAPBootstrapURL = orig }()
+
+	if reg, err := RDAPRegistrar(context.TODO(), "OWASP.org"); err != nil || reg != "Example Registrar, Inc." {
+		t.Errorf("Got: %s, Want: %s, Error: %v", reg, "Example Registrar, Inc.", err)
+	}
+	if _, err := RDAPRegistrar(context.TODO(), "example.com"); err == nil {
+		t.Errorf("Failed to detect the unsuccessful RDAP response")
+	}
+}
+
+func TestRegistrarFromRDAP(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{
+			data: testRDAPResponse,
+			want: "Example Registrar, Inc.",
+		},
+		{
+			data: `{"entities": [{"roles": ["registrant"], "vcardArray": ["vcard", [["fn", {}, "text", "Owner"]]]}]}`,
+			want: "",
+		},
+		{
+			data: `{"entities": [{"roles": ["registrar"], "vcardArray": ["vcard"]}]}`,
+			want: "",
+		},
+		{
+			data: "not json",
+			want: "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := registrarFromRDAP(test.data); got != test.want {
+			t.Errorf("Got: %s, Want: %s", got, test.want)
+		}
+	}
+}
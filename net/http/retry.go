@@ -0,0 +1,102 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how RequestWebPageWithRetry retries the responses that were rate limited,
+// or turned away while the server was unavailable.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of requests sent, including the first one
+	MaxAttempts int
+	// BaseDelay is doubled after each attempt when the response has no Retry-After header
+	BaseDelay time.Duration
+	// Jitter is the upper bound of the random duration added to each delay
+	Jitter time.Duration
+	// MaxDelay is the longest wait accepted, and the response is returned once a longer one is required
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by RequestWebPageWithRetry when no policy is provided.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	Jitter:      500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// RequestWebPageWithRetry sends the request like RequestWebPage, and sends it again when the response
+// has the 429 or 503 status code. The Retry-After header of the response is honored when present, otherwise
+// the delay grows exponentially from the base delay. The last response is returned once the attempts are used.
+func RequestWebPageWithRetry(ctx context.Context, r *Request, policy *RetryPolicy) (*Response, error) {
+	if policy == nil {
+		policy = &DefaultRetryPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := RequestWebPage(ctx, r)
+		if err != nil || attempt >= policy.MaxAttempts || !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		delay, ok := retryAfter(resp.Header, time.Now())
+		if !ok {
+			delay = policy.BaseDelay << (attempt - 1)
+		}
+		if policy.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(policy.Jitter)))
+		}
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			return resp, nil
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return resp, nil
+		case <-t.C:
+		}
+	}
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// retryAfter returns the delay requested by the Retry-After header, provided as seconds or as an HTTP date.
+func retryAfter(hdr Header, now time.Time) (time.Duration, bool) {
+	var value string
+	for k, v := range hdr {
+		if strings.EqualFold(k, "Retry-After") {
+			value = strings.TrimSpace(v)
+			break
+		}
+	}
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,94 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestWebPageWithRetry(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&count, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer ts.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	resp, err := RequestWebPageWithRetry(context.TODO(), &Request{URL: ts.URL}, policy)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Body != "ok" {
+		t.Errorf("Failed to obtain the response after the retries")
+	}
+	if c := atomic.LoadInt32(&count); c != 3 {
+		t.Errorf("Expected 3 requests, got %d", c)
+	}
+
+	atomic.StoreInt32(&count, 0)
+	policy.MaxAttempts = 2
+	resp, err = RequestWebPageWithRetry(context.TODO(), &Request{URL: ts.URL}, policy)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Failed to return the last response once the attempts were used")
+	}
+}
+
+func TestRequestWebPageWithRetryMaxDelay(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+	resp, err := RequestWebPageWithRetry(context.TODO(), &Request{URL: ts.URL}, policy)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Failed to return the rate limited response")
+	}
+	if c := atomic.LoadInt32(&count); c != 1 {
+		t.Errorf("The request was retried despite the Retry-After exceeding the maximum delay")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-5", 0, false},
+		{"Thu, 01 Jun 2023 12:00:30 GMT", 30 * time.Second, true},
+		{"Thu, 01 Jun 2023 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		hdr := Header{}
+		if test.value != "" {
+			hdr["Retry-After"] = test.value
+		}
+
+		if d, ok := retryAfter(hdr, now); d != test.expected || ok != test.ok {
+			t.Errorf("%q: expected %v and %t, got %v and %t", test.value, test.expected, test.ok, d, ok)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package net
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrOffline is returned for outbound requests attempted while the offline mode is enabled.
+var ErrOffline = errors.New("network egress is not permitted in offline mode")
+
+var (
+	egressLock    sync.Mutex
+	egressLimiter *rate.Limiter
+	offline       bool
+)
+
+// SetOffline enables or disables the offline mode. While enabled, all network egress is refused.
+func SetOffline(enabled bool) {
+	egressLock.Lock()
+	defer egressLock.Unlock()
+
+	offline = enabled
+}
+
+// Offline returns true when the offline mode has been enabled.
+func Offline() bool {
+	egressLock.Lock()
+	defer egressLock.Unlock()
+
+	return offline
+}
+
+// SetGlobalQPS sets the maximum number of outbound requests per second shared across
+// all network egress, including DNS queries and HTTP requests. A value of zero removes the limit.
+func SetGlobalQPS(qps int) {
+	egressLock.Lock()
+	defer egressLock.Unlock()
+
+	if qps <= 0 {
+		egressLimiter = nil
+		return
+	}
+	egressLimiter = rate.NewLimiter(rate.Limit(qps), 1)
+}
+
+// WaitForEgress blocks until the global rate limiter permits another outbound request.
+// ErrOffline is returned when the offline mode has been enabled.
+func WaitForEgress(ctx context.Context) error {
+	egressLock.Lock()
+	limiter := egressLimiter
+	disabled := offline
+	egressLock.Unlock()
+
+	if disabled {
+		return ErrOffline
+	}
+	if limiter == nil {
+		return ctx.Err()
+	}
+	return limiter.Wait(ctx)
+}
@@ -0,0 +1,54 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package net
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForEgress(t *testing.T) {
+	defer SetGlobalQPS(0)
+
+	if err := WaitForEgress(context.Background()); err != nil {
+		t.Errorf("Returned an error without a global limit: %v", err)
+	}
+
+	SetGlobalQPS(10)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := WaitForEgress(context.Background()); err != nil {
+			t.Errorf("Returned an error while waiting: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Three requests at 10 QPS only took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitForEgress(ctx); err == nil {
+		t.Errorf("Failed to detect the expired context")
+	}
+}
+
+func TestOffline(t *testing.T) {
+	defer SetOffline(false)
+
+	SetOffline(true)
+	if !Offline() {
+		t.Errorf("Offline returned false after the offline mode was enabled")
+	}
+	if err := WaitForEgress(context.Background()); !errors.Is(err, ErrOffline) {
+		t.Errorf("WaitForEgress returned %v in offline mode", err)
+	}
+
+	SetOffline(false)
+	if err := WaitForEgress(context.Background()); err != nil {
+		t.Errorf("WaitForEgress returned an error after the offline mode was disabled: %v", err)
+	}
+}
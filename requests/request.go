@@ -286,6 +286,8 @@ type AddressInfo struct {
 	CIDRStr     string     `json:"cidr"`
 	ASN         int        `json:"asn"`
 	Description string     `json:"desc"`
+	// The CDN or shared hosting provider the address belongs to
+	CDN string `json:"cdn,omitempty"`
 }
 
 // SanitizeDNSRequest cleans the Name and Domain elements of the receiver.
@@ -5,6 +5,7 @@
 package resources
 
 import (
+	"bufio"
 	"compress/gzip"
 	"embed"
 	"encoding/csv"
@@ -14,9 +15,10 @@ import (
 	"net"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
-//go:embed scripts ip2asn-combined.tsv.gz alterations.txt namelist.txt user_agents.txt
+//go:embed scripts ip2asn-combined.tsv.gz alterations.txt namelist.txt user_agents.txt cdn_ranges.txt
 var resourceFS embed.FS
 
 // IP2ASN is a range record provided by the iptoasn.com service.
@@ -69,6 +71,53 @@ func GetIP2ASNData() ([]*IP2ASN, error) {
 	return ranges, nil
 }
 
+// CDNRange is an address range of a CDN or shared hosting provider.
+type CDNRange struct {
+	Provider string
+	CIDR     *net.IPNet
+}
+
+// GetCDNRanges returns the default CDN and shared hosting ranges read from the 'cdn_ranges.txt' file.
+func GetCDNRanges() ([]*CDNRange, error) {
+	file, err := resourceFS.Open("cdn_ranges.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the 'cdn_ranges.txt' file: %v", err)
+	}
+	defer file.Close()
+
+	return ParseCDNRanges(file)
+}
+
+// ParseCDNRanges reads lines providing the provider name followed by a CIDR. Blank lines and
+// lines starting with a hash are ignored.
+func ParseCDNRanges(r io.Reader) ([]*CDNRange, error) {
+	var ranges []*CDNRange
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d does not provide a provider and a CIDR", num)
+		}
+
+		_, ipnet, err := net.ParseCIDR(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+		ranges = append(ranges, &CDNRange{
+			Provider: fields[0],
+			CIDR:     ipnet,
+		})
+	}
+
+	return ranges, scanner.Err()
+}
+
 func GetDefaultScripts() ([]string, error) {
 	var scripts []string
 
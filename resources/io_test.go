@@ -6,6 +6,7 @@ package resources
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +24,32 @@ func TestGetDefaultScripts(t *testing.T) {
 
 	}
 }
+
+func TestGetCDNRanges(t *testing.T) {
+	ranges, err := GetCDNRanges()
+	if err != nil || len(ranges) == 0 {
+		t.Fatalf("GetCDNRanges() error = %v, returned %d ranges", err, len(ranges))
+	}
+
+	for _, r := range ranges {
+		if r.Provider == "" || r.CIDR == nil {
+			t.Errorf("GetCDNRanges() returned an incomplete range: %+v", r)
+		}
+	}
+}
+
+func TestParseCDNRanges(t *testing.T) {
+	ranges, err := ParseCDNRanges(strings.NewReader("# comment\n\nExample 192.0.2.0/24\nExample 2001:db8::/32\n"))
+	if err != nil || len(ranges) != 2 {
+		t.Fatalf("ParseCDNRanges() error = %v, returned %d ranges", err, len(ranges))
+	}
+	if ranges[0].Provider != "Example" || ranges[0].CIDR.String() != "192.0.2.0/24" {
+		t.Errorf("ParseCDNRanges() returned %s %s", ranges[0].Provider, ranges[0].CIDR)
+	}
+
+	for _, bad := range []string{"Example\n", "Example 192.0.2.0/24 extra\n", "Example 192.0.2.300/24\n"} {
+		if _, err := ParseCDNRanges(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseCDNRanges() did not return an error for %q", bad)
+		}
+	}
+}
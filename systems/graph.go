@@ -0,0 +1,104 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffix/netmap"
+	"github.com/owasp-amass/config/config"
+)
+
+// NewGraphDatabase returns the graph stored in the database, creating the database and running the
+// migrations when they are missing. The local database is the amass.sqlite file in the output directory,
+// while the sqlite scheme provides the path of another SQLite file, such as sqlite:///path/to/amass.db.
+// The pg_trgm extension is created in PostgreSQL databases missing it, when the user has the privileges.
+func NewGraphDatabase(cfg *config.Config, db *config.Database) (*netmap.Graph, error) {
+	var err error
+	var g *netmap.Graph
+
+	switch strings.ToLower(db.System) {
+	case "local":
+		g, err = newGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+	case "sqlite", "sqlite3":
+		var path string
+
+		path, err = sqlitePath(cfg, db.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create the directory of the SQLite database %s: %v", path, err)
+		}
+		// The local graph type opens the SQLite database found at the path
+		g, err = newGraph("local", path, db.Options)
+	default:
+		if db.System == "postgres" {
+			ctx, cancel := context.WithTimeout(context.Background(), pgConnectTimeout)
+			err = ensureTrigramExtension(ctx, db)
+			cancel()
+			if err != nil {
+				return nil, err
+			}
+		}
+		g, err = newGraph(db.System, postgresConnString(db), db.Options)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, fmt.Errorf("failed to create the graph for database: %s", db.System)
+	}
+	return g, nil
+}
+
+// newGraph returns the error of the panics raised by netmap, such as those of failed migrations.
+func newGraph(system, dsn, options string) (g *netmap.Graph, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			g = nil
+			if perr, ok := rec.(error); ok && isTrigramMissing(perr) {
+				err = fmt.Errorf("%w: run CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public; as a superuser", ErrTrigramMissing)
+				return
+			}
+			err = fmt.Errorf("failed to initialize the %s graph database: %v", system, rec)
+		}
+	}()
+
+	return netmap.NewGraph(system, dsn, options), nil
+}
+
+func postgresConnString(db *config.Database) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+}
+
+// sqlitePath returns the file path provided by the SQLite database URI. The host and user are not used
+// by SQLite, so sqlite:///data/amass.db and sqlite://amass@localhost/data/amass.db provide the same path.
+// An opaque URI, such as sqlite:amass.db, provides a path relative to the configuration file.
+func sqlitePath(cfg *config.Config, uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the SQLite database URI: %v", err)
+	}
+
+	if u.Opaque != "" {
+		// The database file is created when it does not exist yet
+		path := filepath.FromSlash(u.Opaque)
+		if !filepath.IsAbs(path) && cfg.Filepath != "" {
+			path = filepath.Join(filepath.Dir(cfg.Filepath), path)
+		}
+		return filepath.Abs(path)
+	}
+	if u.Path == "" || u.Path == "/" {
+		return "", fmt.Errorf("the SQLite database URI %s does not provide a file path", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
@@ -0,0 +1,75 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/owasp-amass/config/config"
+)
+
+func TestSQLitePath(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Filepath = filepath.Join(t.TempDir(), "config.yaml")
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"sqlite:///data/amass.db", "/data/amass.db"},
+		{"sqlite://amass@localhost/data/amass.db", "/data/amass.db"},
+		{"sqlite:amass.db", filepath.Join(filepath.Dir(cfg.Filepath), "amass.db")},
+	}
+
+	for _, test := range tests {
+		if path, err := sqlitePath(cfg, test.uri); err != nil || path != filepath.FromSlash(test.expected) {
+			t.Errorf("URI %s: expected %s, got %s: %v", test.uri, test.expected, path, err)
+		}
+	}
+
+	for _, bad := range []string{"sqlite://", "sqlite:///", "sqlite://%zz"} {
+		if _, err := sqlitePath(cfg, bad); err == nil {
+			t.Errorf("The URI %s did not return an error", bad)
+		}
+	}
+}
+
+func TestNewGraphDatabaseSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "amass.db")
+
+	g, err := NewGraphDatabase(config.NewConfig(), &config.Database{
+		System:  "sqlite",
+		Primary: true,
+		URL:     "sqlite://" + filepath.ToSlash(path),
+	})
+	if err != nil || g == nil {
+		t.Fatalf("Failed to open the SQLite graph database: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("The SQLite database file was not created: %v", err)
+	}
+}
+
+func TestNewGraphDatabaseSQLiteFailure(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("Failed to create the file: %v", err)
+	}
+
+	// The first path is below a regular file, while the second path is a directory
+	for _, path := range []string{filepath.Join(file, "amass.db"), dir} {
+		g, err := NewGraphDatabase(config.NewConfig(), &config.Database{
+			System:  "sqlite",
+			Primary: true,
+			URL:     "sqlite://" + filepath.ToSlash(path),
+		})
+		if err == nil || g != nil {
+			t.Errorf("The SQLite database %s that cannot be created did not return an error", path)
+		}
+	}
+}
@@ -5,19 +5,23 @@
 package systems
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"os"
-	"path/filepath"
 	"runtime"
 	"sort"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/caffix/netmap"
 	"github.com/caffix/service"
 	amassnet "github.com/owasp-amass/amass/v4/net"
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	amasshttp "github.com/owasp-amass/amass/v4/net/http"
 	"github.com/owasp-amass/amass/v4/requests"
 	"github.com/owasp-amass/amass/v4/resources"
 	"github.com/owasp-amass/config/config"
@@ -35,13 +39,49 @@ type LocalSystem struct {
 	doneAlreadyClosed bool
 	addSource         chan service.Service
 	allSources        chan chan []service.Service
+	failedLock        sync.Mutex
+	failed            map[string]error
 }
 
 // NewLocalSystem returns an initialized LocalSystem object.
 func NewLocalSystem(cfg *config.Config) (*LocalSystem, error) {
+	if amassnet.Offline() {
+		return nil, errors.New("the local system requires network access and cannot be created in offline mode")
+	}
 	if err := cfg.CheckSettings(); err != nil {
 		return nil, err
 	}
+	// set the rate limit shared by all network egress
+	if err := setGlobalQPS(cfg); err != nil {
+		return nil, err
+	}
+	// cap the rate of the queries sent to each untrusted resolver
+	if err := setResolverMaxQPS(cfg); err != nil {
+		return nil, err
+	}
+	// set the total number of DNS queries allowed
+	if err := setDNSBudget(cfg); err != nil {
+		return nil, err
+	}
+	// set the EDNS0 client subnet sent with the DNS queries
+	if err := setClientSubnet(cfg); err != nil {
+		return nil, err
+	}
+	// set the maximum size of the HTTP responses read from the data sources
+	if err := setMaxResponseBytes(cfg); err != nil {
+		return nil, err
+	}
+	if err := setTLSConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := SetPublicSuffixList(cfg); err != nil {
+		return nil, err
+	}
+
+	infra, err := InfrastructureEnabled(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	trusted, num := trustedResolvers(cfg)
 	if trusted == nil || num == 0 {
@@ -70,12 +110,15 @@ func NewLocalSystem(cfg *config.Config) (*LocalSystem, error) {
 		done:       make(chan struct{}, 2),
 		addSource:  make(chan service.Service),
 		allSources: make(chan chan []service.Service, 10),
+		failed:     make(map[string]error),
 	}
 
-	// Load the ASN information into the cache
-	if err := sys.loadCacheData(); err != nil {
-		_ = sys.Shutdown()
-		return nil, err
+	// Load the ASN information into the cache, unless the infrastructure lookups are disabled
+	if infra {
+		if err := sys.loadCacheData(); err != nil {
+			_ = sys.Shutdown()
+			return nil, err
+		}
 	}
 	// Make sure that the output directory is setup for this local system
 	if err := sys.setupOutputDirectory(); err != nil {
@@ -92,6 +135,235 @@ func NewLocalSystem(cfg *config.Config) (*LocalSystem, error) {
 	return sys, nil
 }
 
+// setGlobalQPS applies the global_qps option to the rate limiter shared across all network egress.
+func setGlobalQPS(cfg *config.Config) error {
+	var qps int
+
+	if raw, ok := cfg.Options["global_qps"]; ok {
+		switch v := raw.(type) {
+		case int:
+			qps = v
+		case float64:
+			qps = int(v)
+		default:
+			return errors.New("global_qps is not a number")
+		}
+		if qps < 0 {
+			return errors.New("global_qps must be a positive number")
+		}
+	}
+
+	amassnet.SetGlobalQPS(qps)
+	return nil
+}
+
+// setResolverMaxQPS applies the resolver_max_qps option as a ceiling on the queries per second sent to each
+// untrusted resolver, including those loaded from the resolver files. Lower rates set by the -rqps flag are kept.
+func setResolverMaxQPS(cfg *config.Config) error {
+	raw, ok := cfg.Options["resolver_max_qps"]
+	if !ok {
+		return nil
+	}
+
+	var qps int
+	switch v := raw.(type) {
+	case int:
+		qps = v
+	case float64:
+		qps = int(v)
+	default:
+		return errors.New("resolver_max_qps is not a number")
+	}
+	if qps < 0 {
+		return errors.New("resolver_max_qps must be a positive number")
+	}
+
+	if qps > 0 && cfg.ResolversQPS > qps {
+		cfg.ResolversQPS = qps
+	}
+	return nil
+}
+
+// setDNSBudget applies the dns_query_budget option to the count of DNS queries shared by the process.
+func setDNSBudget(cfg *config.Config) error {
+	var budget int
+
+	if raw, ok := cfg.Options["dns_query_budget"]; ok {
+		switch v := raw.(type) {
+		case int:
+			budget = v
+		case float64:
+			budget = int(v)
+		default:
+			return errors.New("dns_query_budget is not a number")
+		}
+		if budget < 0 {
+			return errors.New("dns_query_budget must be a positive number")
+		}
+	}
+
+	amassnet.SetDNSBudget(int64(budget))
+	return nil
+}
+
+// setClientSubnet applies the edns_client_subnet option to the DNS queries built by the process.
+// Without the option, the queries keep sending the 0.0.0.0/0 subnet that hides the location.
+func setClientSubnet(cfg *config.Config) error {
+	var prefix netip.Prefix
+
+	if raw, ok := cfg.Options["edns_client_subnet"]; ok {
+		cidr, ok := raw.(string)
+		if !ok {
+			return errors.New("edns_client_subnet is not a string")
+		}
+
+		var err error
+		prefix, err = netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("edns_client_subnet is not a valid CIDR: %v", err)
+		}
+	}
+
+	amassdns.SetClientSubnet(prefix)
+	return nil
+}
+
+// setMaxResponseBytes applies the http section of the configuration options to the HTTP responses
+// read by the data sources. The oversize_policy is either truncate, the default, or error.
+func setMaxResponseBytes(cfg *config.Config) error {
+	var max int64
+	truncate := true
+
+	if raw, ok := cfg.Options["http"]; ok {
+		settings, ok := raw.(map[string]interface{})
+		if !ok {
+			return errors.New("http is not a map[string]interface{}")
+		}
+
+		if raw, ok := settings["max_response_bytes"]; ok {
+			switch v := raw.(type) {
+			case int:
+				max = int64(v)
+			case float64:
+				max = int64(v)
+			default:
+				return errors.New("http max_response_bytes is not a number")
+			}
+			if max < 0 {
+				return errors.New("http max_response_bytes must be a positive number")
+			}
+		}
+		if raw, ok := settings["oversize_policy"]; ok {
+			switch policy, _ := raw.(string); policy {
+			case "truncate":
+			case "error":
+				truncate = false
+			default:
+				return errors.New("http oversize_policy must be truncate or error")
+			}
+		}
+	}
+
+	amasshttp.SetMaxResponseBytes(max, truncate)
+	return nil
+}
+
+// setTLSConfig applies the tls section of the configuration options to the HTTPS connections made by the
+// data sources. Once the section is provided, the certificates are verified against the system roots and the
+// optional ca_bundle, unless insecure is true. Without the section, the certificates are not verified.
+func setTLSConfig(cfg *config.Config) error {
+	raw, ok := cfg.Options["tls"]
+	if !ok {
+		return nil
+	}
+
+	settings, ok := raw.(map[string]interface{})
+	if !ok {
+		return errors.New("tls is not a map[string]interface{}")
+	}
+
+	var insecure bool
+	if raw, ok := settings["insecure"]; ok {
+		insecure, ok = raw.(bool)
+		if !ok {
+			return errors.New("tls insecure is not a bool")
+		}
+	}
+
+	var roots *x509.CertPool
+	if raw, ok := settings["ca_bundle"]; ok {
+		path, ok := raw.(string)
+		if !ok || path == "" {
+			return errors.New("tls ca_bundle is not a string")
+		}
+
+		var err error
+		roots, err = loadCABundle(cfg, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	amasshttp.SetTLSConfig(roots, insecure)
+	return nil
+}
+
+// loadCABundle returns the system roots along with the certificates in the PEM encoded file.
+func loadCABundle(cfg *config.Config, path string) (*x509.CertPool, error) {
+	abs, err := cfg.AbsPathFromConfigDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the absolute path of the tls ca_bundle: %v", err)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tls ca_bundle: %v", err)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil || roots == nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("the tls ca_bundle %s does not contain any PEM encoded certificates", abs)
+	}
+	return roots, nil
+}
+
+// SetPublicSuffixList loads the file provided by the public_suffix_list option, which is applied ahead of
+// the list embedded in the binary when the registrable domains of the names are derived.
+func SetPublicSuffixList(cfg *config.Config) error {
+	raw, ok := cfg.Options["public_suffix_list"]
+	if !ok {
+		return nil
+	}
+
+	path, ok := raw.(string)
+	if !ok || path == "" {
+		return errors.New("public_suffix_list is not a string")
+	}
+
+	abs, err := cfg.AbsPathFromConfigDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to get the absolute path of the public_suffix_list: %v", err)
+	}
+	return amassdns.SetPublicSuffixFile(abs)
+}
+
+// InfrastructureEnabled returns false when the infrastructure option disables the ASN and netblock lookups.
+func InfrastructureEnabled(cfg *config.Config) (bool, error) {
+	raw, ok := cfg.Options["infrastructure"]
+	if !ok {
+		return true, nil
+	}
+
+	enabled, ok := raw.(bool)
+	if !ok {
+		return false, errors.New("infrastructure is not a bool")
+	}
+	return enabled, nil
+}
+
 // Config implements the System interface.
 func (l *LocalSystem) Config() *config.Config {
 	return l.Cfg
@@ -142,7 +414,13 @@ func (l *LocalSystem) SetDataSources(sources []service.Service) error {
 	// Add all the data sources that successfully start to the list
 	for _, src := range sources {
 		go func(src service.Service, ch chan error) {
-			ch <- l.AddAndStart(src)
+			err := l.AddAndStart(src)
+			if err != nil {
+				l.failedLock.Lock()
+				l.failed[src.String()] = err
+				l.failedLock.Unlock()
+			}
+			ch <- err
 		}(src, ch)
 	}
 
@@ -162,6 +440,18 @@ loop:
 	return err
 }
 
+// FailedDataSources implements the System interface.
+func (l *LocalSystem) FailedDataSources() map[string]error {
+	l.failedLock.Lock()
+	defer l.failedLock.Unlock()
+
+	failed := make(map[string]error, len(l.failed))
+	for name, err := range l.failed {
+		failed[name] = err
+	}
+	return failed
+}
+
 // GraphDatabases implements the System interface.
 func (l *LocalSystem) GraphDatabases() []*netmap.Graph {
 	return l.graphs
@@ -201,16 +491,39 @@ func (l *LocalSystem) setupOutputDirectory() error {
 	if path == "" {
 		return nil
 	}
+	return CheckOutputDirectory(path)
+}
 
-	var err error
+// ErrOutputDirNotWritable is returned when the output directory cannot be created or written to.
+var ErrOutputDirNotWritable = errors.New("the output directory is not writable")
+
+// CheckOutputDirectory creates the output directory when it does not yet exist and
+// verifies that files can be written to it before any of the engine is started.
+func CheckOutputDirectory(path string) error {
 	// If the directory does not yet exist, create it
-	if err = os.MkdirAll(path, 0755); err != nil {
-		return nil
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return outputDirError(path, err)
+	}
+
+	f, err := os.CreateTemp(path, ".amass-write-check-*")
+	if err != nil {
+		return outputDirError(path, err)
 	}
 
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
 	return nil
 }
 
+func outputDirError(path string, err error) error {
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return fmt.Errorf("%w: %s requires write and execute permission for the current user "+
+			"(e.g. chmod u+wx %s) or use the -dir flag to select another directory: %v", ErrOutputDirNotWritable, path, path, err)
+	}
+	return fmt.Errorf("failed to setup the output directory %s: %v", path, err)
+}
+
 // Select the graph that will store the System findings.
 func (l *LocalSystem) setupGraphDBs(cfg *config.Config) error {
 	// Add the local database settings to the configuration
@@ -218,17 +531,9 @@ func (l *LocalSystem) setupGraphDBs(cfg *config.Config) error {
 
 	for _, db := range cfg.GraphDBs {
 		if db.Primary {
-			var g *netmap.Graph
-
-			if db.System == "local" {
-				g = netmap.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
-			} else {
-				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
-				g = netmap.NewGraph(db.System, connStr, db.Options)
-			}
-
-			if g == nil {
-				return fmt.Errorf("System: failed to create the graph for database: %s", db.System)
+			g, err := NewGraphDatabase(cfg, db)
+			if err != nil {
+				return fmt.Errorf("System: %v", err)
 			}
 
 			l.graphs = append(l.graphs, g)
@@ -269,6 +574,11 @@ func (l *LocalSystem) manageDataSources() {
 }
 
 func (l *LocalSystem) loadCacheData() error {
+	return LoadASNCache(l.cache)
+}
+
+// LoadASNCache populates the provided cache with the IP2ASN data included with Amass.
+func LoadASNCache(cache *requests.ASNCache) error {
 	ranges, err := resources.GetIP2ASNData()
 	if err != nil {
 		return err
@@ -283,7 +593,7 @@ func (l *LocalSystem) loadCacheData() error {
 			continue
 		}
 
-		l.cache.Update(&requests.ASNRequest{
+		cache.Update(&requests.ASNRequest{
 			Address:     r.FirstIP.String(),
 			ASN:         r.ASN,
 			CC:          r.CC,
@@ -5,8 +5,16 @@
 package systems
 
 import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"testing"
+
+	amassdns "github.com/owasp-amass/amass/v4/net/dns"
+	"github.com/owasp-amass/config/config"
 )
 
 func TestCheckAddresses(t *testing.T) {
@@ -56,3 +64,95 @@ func TestCheckAddresses(t *testing.T) {
 		})
 	}
 }
+
+func TestSetResolverMaxQPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		qps      int
+		option   interface{}
+		expected int
+		err      bool
+	}{
+		{name: "No option", qps: 15, expected: 15},
+		{name: "Ceiling below the rate", qps: 15, option: 5, expected: 5},
+		{name: "Ceiling above the rate", qps: 15, option: 20, expected: 15},
+		{name: "Float ceiling", qps: 15, option: 8.0, expected: 8},
+		{name: "Zero disables the ceiling", qps: 15, option: 0, expected: 15},
+		{name: "Negative ceiling", qps: 15, option: -1, err: true},
+		{name: "Not a number", qps: 15, option: "ten", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.ResolversQPS = tt.qps
+			if tt.option != nil {
+				cfg.Options["resolver_max_qps"] = tt.option
+			}
+
+			err := setResolverMaxQPS(cfg)
+			if tt.err {
+				if err == nil {
+					t.Errorf("setResolverMaxQPS accepted the option %v", tt.option)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setResolverMaxQPS failed: %v", err)
+			}
+			if cfg.ResolversQPS != tt.expected {
+				t.Errorf("Unexpected Result, expected %d, got %d", tt.expected, cfg.ResolversQPS)
+			}
+		})
+	}
+}
+
+func TestCheckOutputDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "amass")
+	if err := CheckOutputDirectory(dir); err != nil {
+		t.Fatalf("CheckOutputDirectory failed to setup a writable directory: %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("CheckOutputDirectory left files in the output directory")
+	}
+
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for this user")
+	}
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatalf("failed to change the directory permissions: %v", err)
+	}
+	defer func() { _ = os.Chmod(dir, 0755) }()
+
+	if err := CheckOutputDirectory(dir); !errors.Is(err, ErrOutputDirNotWritable) {
+		t.Errorf("CheckOutputDirectory returned %v for a read-only directory", err)
+	}
+	if err := CheckOutputDirectory(filepath.Join(dir, "sub")); !errors.Is(err, ErrOutputDirNotWritable) {
+		t.Errorf("CheckOutputDirectory returned %v for a directory that cannot be created", err)
+	}
+}
+
+func TestSetClientSubnet(t *testing.T) {
+	defer amassdns.SetClientSubnet(netip.Prefix{})
+
+	cfg := config.NewConfig()
+	cfg.Options["edns_client_subnet"] = "203.0.113.0/24"
+	if err := setClientSubnet(cfg); err != nil {
+		t.Fatalf("failed to set a valid subnet: %v", err)
+	}
+	if got := amassdns.ClientSubnet(); got != netip.MustParsePrefix("203.0.113.0/24") {
+		t.Errorf("unexpected client subnet %s", got)
+	}
+
+	for _, bad := range []interface{}{"203.0.113.0", "not a cidr", 24} {
+		cfg.Options["edns_client_subnet"] = bad
+		if err := setClientSubnet(cfg); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+
+	delete(cfg.Options, "edns_client_subnet")
+	if err := setClientSubnet(cfg); err != nil || amassdns.ClientSubnet().IsValid() {
+		t.Errorf("expected the unset option to restore the default subnet")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/owasp-amass/config/config"
+)
+
+// The time allowed for the PostgreSQL server to accept the connection and answer the checks.
+const pgConnectTimeout = 10 * time.Second
+
+// SQLSTATE codes returned by PostgreSQL.
+const (
+	pgInsufficientPrivilege = "42501"
+	pgUndefinedObject       = "42704"
+)
+
+// ErrTrigramMissing is returned when the pg_trgm extension, required by the gin_trgm_ops
+// indexes of the graph database, is not installed and could not be created.
+var ErrTrigramMissing = errors.New("the pg_trgm extension is missing from the postgres database")
+
+// ensureTrigramExtension connects to the PostgreSQL database and creates the pg_trgm extension
+// when it is missing, which only succeeds when the user has the privileges to do so.
+func ensureTrigramExtension(ctx context.Context, db *config.Database) error {
+	conn, err := connectPostgres(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if installed, err := trigramInstalled(ctx, conn, db); err != nil || installed {
+		return err
+	}
+
+	if _, err := conn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public"); err != nil {
+		return trigramError(db, err)
+	}
+	return nil
+}
+
+// checkTrigramExtension connects to the PostgreSQL database and reports when the pg_trgm extension is
+// missing and the user lacks the privileges to create it. The database is not changed, since the
+// extension is created by NewGraphDatabase.
+func checkTrigramExtension(ctx context.Context, db *config.Database) error {
+	conn, err := connectPostgres(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if installed, err := trigramInstalled(ctx, conn, db); err != nil || installed {
+		return err
+	}
+
+	var allowed bool
+	if err := conn.QueryRow(ctx, "SELECT rolsuper OR has_database_privilege(current_database(), 'CREATE') "+
+		"FROM pg_roles WHERE rolname = current_user").Scan(&allowed); err != nil {
+		return fmt.Errorf("failed to query the privileges of the user %s: %v", db.Username, err)
+	}
+	if !allowed {
+		return trigramError(db, fmt.Errorf("the user %s lacks the privileges to create it", db.Username))
+	}
+	return nil
+}
+
+func connectPostgres(ctx context.Context, db *config.Database) (*pgx.Conn, error) {
+	pgcfg, err := pgx.ParseConfig(postgresConnString(db))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the postgres database connection: %v", err)
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, pgcfg)
+	if err != nil {
+		return nil, fmt.Errorf("the postgres database %s at %s cannot be reached: %v", db.DBName, db.Host, err)
+	}
+	return conn, nil
+}
+
+func trigramInstalled(ctx context.Context, conn *pgx.Conn, db *config.Database) (bool, error) {
+	var installed bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&installed); err != nil {
+		return false, fmt.Errorf("failed to query the extensions of the postgres database %s: %v", db.DBName, err)
+	}
+	return installed, nil
+}
+
+func trigramError(db *config.Database, err error) error {
+	reason := err.Error()
+
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) && pgerr.Code == pgInsufficientPrivilege {
+		reason = fmt.Sprintf("the user %s lacks the privileges to create it", db.Username)
+	}
+	return fmt.Errorf("%w: run CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public; "+
+		"as a superuser in the %s database (%s)", ErrTrigramMissing, db.DBName, reason)
+}
+
+// isTrigramMissing returns true when the error was caused by a gin_trgm_ops index created without
+// the pg_trgm extension. The migrations do not wrap the errors, so the message is also checked.
+func isTrigramMissing(err error) bool {
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) {
+		return pgerr.Code == pgUndefinedObject && strings.Contains(pgerr.Message, "gin_trgm_ops")
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "SQLSTATE "+pgUndefinedObject) && strings.Contains(msg, "gin_trgm_ops")
+}
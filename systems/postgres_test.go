@@ -0,0 +1,48 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/owasp-amass/config/config"
+)
+
+func TestIsTrigramMissing(t *testing.T) {
+	pgerr := &pgconn.PgError{
+		Code:    pgUndefinedObject,
+		Message: `operator class "gin_trgm_ops" does not exist for access method "gin"`,
+	}
+
+	if !isTrigramMissing(pgerr) {
+		t.Errorf("The PostgreSQL error was not detected")
+	}
+	// The migrations only keep the message of the error
+	if !isTrigramMissing(errors.New(pgerr.Error() + " handling 005_assets_indexes.sql")) {
+		t.Errorf("The message of the migration error was not detected")
+	}
+	if isTrigramMissing(&pgconn.PgError{Code: pgUndefinedObject, Message: `type "foo" does not exist`}) {
+		t.Errorf("Another undefined object was detected as the missing extension")
+	}
+	if isTrigramMissing(fmt.Errorf("connection refused")) {
+		t.Errorf("A connection error was detected as the missing extension")
+	}
+}
+
+func TestTrigramError(t *testing.T) {
+	db := &config.Database{System: "postgres", Username: "amass", DBName: "assetdb"}
+
+	err := trigramError(db, &pgconn.PgError{Code: pgInsufficientPrivilege, Message: "permission denied to create extension"})
+	if !errors.Is(err, ErrTrigramMissing) {
+		t.Errorf("The error does not wrap ErrTrigramMissing: %v", err)
+	}
+	if !strings.Contains(err.Error(), "the user amass lacks the privileges") {
+		t.Errorf("The error does not explain the missing privileges: %v", err)
+	}
+}
@@ -58,6 +58,9 @@ func (ss *SimpleSystem) SetDataSources(sources []service.Service) error {
 	return nil
 }
 
+// FailedDataSources implements the System interface.
+func (ss *SimpleSystem) FailedDataSources() map[string]error { return nil }
+
 // GraphDatabases implements the System interface.
 func (ss *SimpleSystem) GraphDatabases() []*netmap.Graph { return []*netmap.Graph{ss.Graph} }
 
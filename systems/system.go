@@ -41,6 +41,9 @@ type System interface {
 	// SetDataSources assigns the data sources that will be used by System
 	SetDataSources(sources []service.Service) error
 
+	// FailedDataSources returns the errors of the data sources that failed to start, keyed by name
+	FailedDataSources() map[string]error
+
 	// GraphDatabases return the Graphs used by the System
 	GraphDatabases() []*netmap.Graph
 
@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/owasp-amass/config/config"
+)
+
+// ValidateConfig reports the problems in the configuration that would otherwise surface as failures,
+// or panics, once the system starts. The resolver and wordlist files are checked as the configuration
+// is loaded, so this checks the graph databases, the scripts directory and that the scope is not empty.
+func ValidateConfig(ctx context.Context, cfg *config.Config) error {
+	if len(cfg.Domains()) == 0 && len(cfg.Scope.Addresses) == 0 &&
+		len(cfg.Scope.CIDRs) == 0 && len(cfg.Scope.ASNs) == 0 {
+		return errors.New("no root domain names, addresses, CIDRs or ASNs were provided in the scope")
+	}
+	if dir := cfg.ScriptsDirectory; dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("the scripts directory %s does not exist", dir)
+		}
+	}
+	return ValidateDatabases(ctx, cfg)
+}
+
+// ValidateDatabases checks that the connection of each SQLite and PostgreSQL graph database in the
+// configuration can be parsed, and that the PostgreSQL servers are reachable and either provide the
+// pg_trgm extension or allow the user to create it. The other systems are left to NewGraphDatabase.
+func ValidateDatabases(ctx context.Context, cfg *config.Config) error {
+	for _, db := range cfg.GraphDBs {
+		if err := validateDatabase(ctx, cfg, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDatabase(ctx context.Context, cfg *config.Config, db *config.Database) error {
+	switch strings.ToLower(db.System) {
+	case "sqlite", "sqlite3":
+		_, err := sqlitePath(cfg, db.URL)
+		return err
+	case "postgres":
+		ctx, cancel := context.WithTimeout(ctx, pgConnectTimeout)
+		defer cancel()
+
+		return checkTrigramExtension(ctx, db)
+	}
+	return nil
+}
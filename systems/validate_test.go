@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2023. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package systems
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/owasp-amass/config/config"
+)
+
+func TestValidateConfigScope(t *testing.T) {
+	cfg := config.NewConfig()
+	if err := ValidateConfig(context.Background(), cfg); err == nil {
+		t.Errorf("An empty scope did not return an error")
+	}
+
+	cfg.AddDomain("owasp.org")
+	if err := ValidateConfig(context.Background(), cfg); err != nil {
+		t.Errorf("A scope with a root domain name returned an error: %v", err)
+	}
+
+	cfg.ScriptsDirectory = filepath.Join(t.TempDir(), "missing")
+	if err := ValidateConfig(context.Background(), cfg); err == nil {
+		t.Errorf("A missing scripts directory did not return an error")
+	}
+}
+
+func TestValidateDatabases(t *testing.T) {
+	// Find a local port that is not accepting connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open a listener: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	tests := []struct {
+		db    *config.Database
+		valid bool
+	}{
+		{&config.Database{System: "local", Primary: true}, true},
+		{&config.Database{System: "sqlite", Primary: true, URL: "sqlite:///data/amass.db"}, true},
+		{&config.Database{System: "sqlite", Primary: true, URL: "sqlite://"}, false},
+		{&config.Database{System: "mysql", Primary: true, Host: "127.0.0.1"}, true},
+		{&config.Database{System: "postgres", Primary: true, Host: "127.0.0.1", Port: port, Username: "amass", DBName: "assetdb"}, false},
+	}
+
+	for _, test := range tests {
+		cfg := config.NewConfig()
+		cfg.GraphDBs = []*config.Database{test.db}
+
+		if err := ValidateDatabases(context.Background(), cfg); (err == nil) != test.valid {
+			t.Errorf("Database system %s at %s%s: expected valid %t, got the error %v",
+				test.db.System, test.db.Host, test.db.URL, test.valid, err)
+		}
+	}
+}